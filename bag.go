@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ArturM94/pokedexcli/internal/trainer"
+)
+
+// ballModifiers multiplies the catch rate when throwing a given ball.
+var ballModifiers = map[string]float64{
+	"poke-ball":   1,
+	"great-ball":  1.5,
+	"ultra-ball":  2,
+	"master-ball": 255,
+}
+
+// healAmounts is how much HP a healing item restores. Zero means fully.
+var healAmounts = map[string]int{
+	"potion":       20,
+	"super-potion": 60,
+	"hyper-potion": 200,
+	"max-potion":   0,
+}
+
+func commandBag(ctx *commandContext) error {
+	if len(ctx.Trainer.Bag) == 0 {
+		fmt.Println("Your bag is empty.")
+
+		return nil
+	}
+
+	names := make([]string, 0, len(ctx.Trainer.Bag))
+	for name := range ctx.Trainer.Bag {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Println("Your bag:")
+	for _, name := range names {
+		fmt.Printf(" - %s x%d\n", name, ctx.Trainer.Bag[name])
+	}
+
+	return nil
+}
+
+func commandItem(ctx *commandContext) error {
+	if len(ctx.Args) == 0 {
+		fmt.Println("usage: item <name>")
+
+		return nil
+	}
+
+	item, err := ctx.Client.GetItem(resourceName(ctx.Args[0]))
+	if err != nil {
+		return fmt.Errorf("error getting item: %w", err)
+	}
+
+	indexLocalized(ctx, "item", item.Name, item.Names)
+
+	fmt.Println("Name:", item.Name)
+	fmt.Println("Category:", item.Category.Name)
+	fmt.Println("Cost:", item.Cost)
+	fmt.Println("In bag:", ctx.Trainer.ItemCount(item.Name))
+
+	for _, entry := range item.EffectEntries {
+		if entry.Language.Name != "en" {
+			continue
+		}
+
+		fmt.Println("Effect:", strings.Join(strings.Fields(entry.ShortEffect), " "))
+	}
+
+	return nil
+}
+
+func commandUse(ctx *commandContext) error {
+	if len(ctx.Args) == 0 {
+		fmt.Println("usage: use <item> [target]")
+
+		return nil
+	}
+
+	itemName := resourceName(ctx.Args[0])
+	if ctx.Trainer.ItemCount(itemName) == 0 {
+		fmt.Println("You don't have any " + itemName + "!")
+
+		return nil
+	}
+
+	if _, ok := ballModifiers[itemName]; ok {
+		fmt.Println("Throw " + itemName + " with: catch <pokemon> " + itemName)
+
+		return nil
+	}
+
+	if amount, ok := healAmounts[itemName]; ok {
+		if len(ctx.Args) < 2 {
+			fmt.Println("usage: use " + itemName + " <pokemon>")
+
+			return nil
+		}
+
+		target := strings.Join(ctx.Args[1:], " ")
+		if ctx.Config.Battle != nil {
+			fmt.Println("You can't use items during a battle.")
+
+			return nil
+		}
+
+		p, err := resolvePokemon(ctx, target)
+		if err != nil || p == nil {
+			return err
+		}
+
+		species, err := ctx.Client.GetPokemon(p.Species)
+		if err != nil {
+			return fmt.Errorf("error getting pokemon: %w", err)
+		}
+
+		maxHP := pokemonStats(species, p).HP
+
+		switch {
+		case p.HP == 0:
+			fmt.Println(p.Name() + " has fainted and can't be healed with " + itemName + ".")
+
+			return nil
+		case p.HP >= maxHP:
+			fmt.Println(p.Name() + " is already at full health.")
+
+			return nil
+		}
+
+		var healed int
+		err = ctx.Trainer.Transact(ctx.SavePath, func(t *trainer.Trainer) error {
+			if !t.RemoveItem(itemName, 1) {
+				return fmt.Errorf("you don't have any %s", itemName)
+			}
+
+			p := t.PokemonByID(p.ID)
+			before := p.HP
+			if amount == 0 {
+				p.HP = maxHP
+			} else {
+				p.HP = min(p.HP+amount, maxHP)
+			}
+			healed = p.HP - before
+
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("error using %s: %w", itemName, err)
+		}
+
+		fmt.Printf("%s recovered %d HP.\n", p.Name(), healed)
+
+		return nil
+	}
+
+	fmt.Println(itemName + " can't be used here.")
+
+	return nil
+}
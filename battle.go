@@ -0,0 +1,654 @@
+package main
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+
+	"github.com/ArturM94/pokedexcli/internal/battle"
+	"github.com/ArturM94/pokedexcli/internal/pokeapi"
+	"github.com/ArturM94/pokedexcli/internal/trainer"
+)
+
+// encounter is a wild Pokemon that can be met in the last explored area.
+type encounter struct {
+	Name     string
+	DexID    int
+	MinLevel int
+	MaxLevel int
+}
+
+type activeBattle struct {
+	fight     *battle.Battle
+	leadIndex int
+	// wild is the Pokemon rolled for the encounter, which catch takes when
+	// it succeeds so the one caught is the one that appeared.
+	wild               *trainer.Pokemon
+	wildBaseExperience int
+	wildEffort         battle.Stats
+}
+
+func commandBattle(ctx *commandContext) error {
+	if ctx.Config.Battle != nil {
+		fmt.Println("You're already in a battle!")
+
+		return nil
+	}
+
+	leadIndex := slices.IndexFunc(ctx.Trainer.Party, func(p *trainer.Pokemon) bool {
+		return p.HP > 0
+	})
+	if leadIndex == -1 {
+		fmt.Println("You have no Pokemon that can battle!")
+
+		return nil
+	}
+
+	if len(ctx.Config.Encounters) == 0 {
+		fmt.Println("Explore an area first to find wild Pokemon.")
+
+		return nil
+	}
+
+	wildEncounter := ctx.Config.Encounters[ctx.Rand.IntN(len(ctx.Config.Encounters))]
+	if len(ctx.Args) > 0 {
+		i := slices.IndexFunc(ctx.Config.Encounters, func(e encounter) bool {
+			return e.Name == resourceName(ctx.Args[0])
+		})
+		if i == -1 {
+			fmt.Println(ctx.Args[0] + " can't be found here.")
+
+			return nil
+		}
+
+		wildEncounter = ctx.Config.Encounters[i]
+	}
+
+	wildSpecies, err := ctx.Client.GetPokemon(wildEncounter.Name)
+	if err != nil {
+		return fmt.Errorf("error getting pokemon: %w", err)
+	}
+
+	dexID, err := dexNumber(wildSpecies)
+	if err != nil {
+		return err
+	}
+
+	err = ctx.Trainer.Transact(ctx.SavePath, func(t *trainer.Trainer) error {
+		t.See(dexID, wildSpecies.Name)
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error saving trainer: %w", err)
+	}
+
+	wild, err := newTrainerPokemon(ctx, wildSpecies, encounterLevel(ctx, wildEncounter.Name))
+	if err != nil {
+		return err
+	}
+
+	wildFighter, err := newFighter(ctx, wildSpecies, wild)
+	if err != nil {
+		return err
+	}
+
+	lead := ctx.Trainer.Party[leadIndex]
+
+	leadSpecies, err := ctx.Client.GetPokemon(lead.Species)
+	if err != nil {
+		return fmt.Errorf("error getting pokemon: %w", err)
+	}
+
+	leadFighter, err := newFighter(ctx, leadSpecies, lead)
+	if err != nil {
+		return err
+	}
+
+	ctx.Config.Battle = &activeBattle{
+		fight:              battle.New(leadFighter, wildFighter, ctx.Rand),
+		leadIndex:          leadIndex,
+		wild:               wild,
+		wildBaseExperience: wildSpecies.BaseExperience,
+		wildEffort:         effortYield(wildSpecies),
+	}
+
+	if wild.Shiny {
+		fmt.Printf("A wild shiny %s (Lv. %d) appeared!\n", wildFighter.Name, wildFighter.Level)
+	} else {
+		fmt.Printf("A wild %s (Lv. %d) appeared!\n", wildFighter.Name, wildFighter.Level)
+	}
+	fmt.Printf("Go! %s (Lv. %d)!\n", leadFighter.Name, leadFighter.Level)
+	printMoves(leadFighter)
+
+	return nil
+}
+
+func commandAttack(ctx *commandContext) error {
+	active := ctx.Config.Battle
+	if active == nil {
+		fmt.Println("You're not in a battle.")
+
+		return nil
+	}
+
+	player := active.fight.Player
+	if len(ctx.Args) == 0 {
+		printMoves(player)
+
+		return nil
+	}
+
+	moveIndex := moveIndexOf(player, resourceName(ctx.Args[0]))
+	if n, err := strconv.Atoi(ctx.Args[0]); err == nil {
+		moveIndex = n - 1
+	} else if moveIndex == -1 {
+		name, ok := correctName(ctx, "move", resourceName(ctx.Args[0]))
+		if !ok {
+			return nil
+		}
+
+		moveIndex = moveIndexOf(player, name)
+		if moveIndex == -1 {
+			fmt.Println(player.Name + " doesn't know " + name + "!")
+			printMoves(player)
+
+			return nil
+		}
+	}
+
+	attacks, err := active.fight.Turn(moveIndex)
+	if err != nil {
+		return fmt.Errorf("error attacking: %w", err)
+	}
+
+	for _, a := range attacks {
+		printAttack(a)
+	}
+
+	won := active.fight.Winner() == player
+	reward := 0
+	if won {
+		reward = battleReward(active.wildBaseExperience, active.fight.Wild.Level)
+	}
+
+	var growth []string
+	err = ctx.Trainer.Transact(ctx.SavePath, func(t *trainer.Trainer) error {
+		lead := t.Party[active.leadIndex]
+		lead.HP = player.HP
+		for i, move := range player.Moves {
+			lead.Moves[i].PP = move.PP
+		}
+
+		if !won {
+			return nil
+		}
+
+		t.Money += reward
+		lead.GainEVs(active.wildEffort)
+
+		var err error
+		exp := battle.ExperienceYield(active.wildBaseExperience, active.fight.Wild.Level)
+		growth, err = gainExperience(ctx, lead, exp)
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("error saving trainer: %w", err)
+	}
+
+	switch active.fight.Winner() {
+	case player:
+		fmt.Printf("You won! You earned $%d.\n", reward)
+		for _, message := range growth {
+			fmt.Println(message)
+		}
+		ctx.Config.Battle = nil
+	case active.fight.Wild:
+		fmt.Println("You lost the battle...")
+		ctx.Config.Battle = nil
+	default:
+		fmt.Printf("%s HP: %d/%d | wild %s HP: %d/%d\n",
+			player.Name, player.HP, player.Stats.HP,
+			active.fight.Wild.Name, active.fight.Wild.HP, active.fight.Wild.Stats.HP)
+	}
+
+	return nil
+}
+
+func commandRun(ctx *commandContext) error {
+	if ctx.Config.Battle == nil {
+		fmt.Println("You're not in a battle.")
+
+		return nil
+	}
+
+	ctx.Config.Battle = nil
+	fmt.Println("Got away safely!")
+
+	return nil
+}
+
+func commandHeal(ctx *commandContext) error {
+	if inBattle(ctx) {
+		return nil
+	}
+
+	err := ctx.Trainer.Transact(ctx.SavePath, func(t *trainer.Trainer) error {
+		for _, p := range t.Party {
+			species, err := ctx.Client.GetPokemon(p.Species)
+			if err != nil {
+				return fmt.Errorf("error getting pokemon: %w", err)
+			}
+
+			p.HP = pokemonStats(species, p).HP
+			for i := range p.Moves {
+				p.Moves[i].PP = p.Moves[i].MaxPP
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Your Pokemon are fighting fit!")
+
+	return nil
+}
+
+// moveIndexOf finds the move called name among p's moves, or returns -1.
+func moveIndexOf(p *battle.Pokemon, name string) int {
+	return slices.IndexFunc(p.Moves, func(m *battle.Move) bool {
+		return m.Name == name
+	})
+}
+
+func printMoves(p *battle.Pokemon) {
+	fmt.Println("Moves:")
+	for i, move := range p.Moves {
+		fmt.Printf("  %d. %s (%s) PP %d/%d\n", i+1, move.Name, move.Type, move.PP, move.MaxPP)
+	}
+}
+
+func printAttack(a battle.Attack) {
+	fmt.Printf("%s used %s!\n", a.Attacker.Name, a.Move.Name)
+
+	switch {
+	case a.Missed:
+		fmt.Println("The attack missed!")
+	case a.Move.DamageClass == battle.Status || a.Move.Power == 0:
+		fmt.Println("Nothing happened.")
+	case a.Effectiveness == 0:
+		fmt.Println("It doesn't affect " + a.Defender.Name + "...")
+	default:
+		if a.Critical {
+			fmt.Println("A critical hit!")
+		}
+		if a.Effectiveness > 1 {
+			fmt.Println("It's super effective!")
+		}
+		if a.Effectiveness < 1 {
+			fmt.Println("It's not very effective...")
+		}
+		fmt.Printf("%s took %d damage.\n", a.Defender.Name, a.Damage)
+	}
+
+	if a.Fainted {
+		fmt.Println(a.Defender.Name + " fainted!")
+	}
+}
+
+// battleReward is the prize money for defeating a wild Pokemon.
+func battleReward(baseExperience, level int) int {
+	return baseExperience * level / 7
+}
+
+// newTrainerPokemon creates a Pokemon of species at level with random IVs,
+// nature, gender and shininess, full HP and the most recent level-up moves
+// it knows.
+func newTrainerPokemon(ctx *commandContext, species *pokeapi.GetPokemonResponse, level int) (*trainer.Pokemon, error) {
+	p := &trainer.Pokemon{
+		Species: species.Name,
+		Level:   level,
+		IVs:     randomIVs(ctx.Rand),
+		Shiny:   rollShiny(ctx.Rand, ctx.Config.ShinyOdds),
+	}
+
+	natures, err := ctx.Client.GetNatures()
+	if err != nil {
+		return nil, fmt.Errorf("error getting natures: %w", err)
+	}
+
+	if len(natures.Results) > 0 {
+		nature, err := ctx.Client.GetNature(natures.Results[ctx.Rand.IntN(len(natures.Results))].Name)
+		if err != nil {
+			return nil, fmt.Errorf("error getting nature: %w", err)
+		}
+
+		p.Nature = battle.Nature{
+			Name:      nature.Name,
+			Increased: nature.IncreasedStat.Name,
+			Decreased: nature.DecreasedStat.Name,
+		}
+	}
+
+	speciesDetails, err := ctx.Client.GetPokemonSpecies(species.Species.Name)
+	if err != nil {
+		return nil, fmt.Errorf("error getting species: %w", err)
+	}
+
+	indexLocalized(ctx, "pokemon", species.Name, speciesDetails.Names)
+
+	p.Gender = randomGender(ctx.Rand, speciesDetails.GenderRate)
+	p.HP = pokemonStats(species, p).HP
+
+	growth, err := growthRate(ctx, speciesDetails.GrowthRate.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Experience = growth.ExperienceFor(level)
+
+	for _, name := range levelUpMoves(species, level) {
+		move, err := ctx.Client.GetMove(name)
+		if err != nil {
+			return nil, fmt.Errorf("error getting move: %w", err)
+		}
+
+		p.Moves = append(p.Moves, trainer.Move{Name: move.Name, PP: move.PP, MaxPP: move.PP})
+	}
+
+	return p, nil
+}
+
+// newFighter converts a Pokemon into its battle engine form.
+func newFighter(ctx *commandContext, species *pokeapi.GetPokemonResponse, p *trainer.Pokemon) (*battle.Pokemon, error) {
+	fighter := &battle.Pokemon{
+		Name:  p.Name(),
+		Level: p.Level,
+		Types: typeNames(species),
+		Stats: pokemonStats(species, p),
+		HP:    p.HP,
+	}
+
+	for _, m := range p.Moves {
+		move, err := ctx.Client.GetMove(m.Name)
+		if err != nil {
+			return nil, fmt.Errorf("error getting move: %w", err)
+		}
+
+		fighter.Moves = append(fighter.Moves, &battle.Move{
+			Name:        move.Name,
+			Type:        move.Type.Name,
+			DamageClass: move.DamageClass.Name,
+			Power:       valueOrZero(move.Power),
+			Accuracy:    valueOrZero(move.Accuracy),
+			PP:          m.PP,
+			MaxPP:       m.MaxPP,
+			Priority:    move.Priority,
+		})
+	}
+
+	return fighter, nil
+}
+
+// pokemonStats returns the actual stats of an owned or wild Pokemon.
+func pokemonStats(species *pokeapi.GetPokemonResponse, p *trainer.Pokemon) battle.Stats {
+	return battle.CalcStats(baseStats(species), p.IVs, p.EVs, p.Level, p.Nature)
+}
+
+// rollShiny decides if an encountered Pokemon is shiny, with a 1 in odds
+// chance. Odds below 1 turn shiny Pokemon off.
+func rollShiny(rng *rand.Rand, odds int) bool {
+	return odds > 0 && rng.IntN(odds) == 0
+}
+
+func randomIVs(rng *rand.Rand) battle.Stats {
+	return battle.Stats{
+		HP:             rng.IntN(32),
+		Attack:         rng.IntN(32),
+		Defense:        rng.IntN(32),
+		SpecialAttack:  rng.IntN(32),
+		SpecialDefense: rng.IntN(32),
+		Speed:          rng.IntN(32),
+	}
+}
+
+// randomGender rolls a gender from the species gender rate, which is the
+// chance of being female in eighths, or -1 for genderless species.
+func randomGender(rng *rand.Rand, genderRate int) string {
+	switch {
+	case genderRate < 0:
+		return "genderless"
+	case rng.IntN(8) < genderRate:
+		return "female"
+	default:
+		return "male"
+	}
+}
+
+func describeNature(n battle.Nature) string {
+	if n.Name == "" {
+		return "unknown"
+	}
+
+	if n.Increased == n.Decreased {
+		return n.Name
+	}
+
+	return fmt.Sprintf("%s (+%s, -%s)", n.Name, n.Increased, n.Decreased)
+}
+
+// effortYield is the effort values earned by defeating species.
+func effortYield(species *pokeapi.GetPokemonResponse) battle.Stats {
+	var yield battle.Stats
+
+	for _, s := range species.Stats {
+		switch s.Stat.Name {
+		case "hp":
+			yield.HP = s.Effort
+		case "attack":
+			yield.Attack = s.Effort
+		case "defense":
+			yield.Defense = s.Effort
+		case "special-attack":
+			yield.SpecialAttack = s.Effort
+		case "special-defense":
+			yield.SpecialDefense = s.Effort
+		case "speed":
+			yield.Speed = s.Effort
+		}
+	}
+
+	return yield
+}
+
+func baseStats(species *pokeapi.GetPokemonResponse) battle.Stats {
+	var stats battle.Stats
+
+	for _, s := range species.Stats {
+		switch s.Stat.Name {
+		case "hp":
+			stats.HP = s.BaseStat
+		case "attack":
+			stats.Attack = s.BaseStat
+		case "defense":
+			stats.Defense = s.BaseStat
+		case "special-attack":
+			stats.SpecialAttack = s.BaseStat
+		case "special-defense":
+			stats.SpecialDefense = s.BaseStat
+		case "speed":
+			stats.Speed = s.BaseStat
+		}
+	}
+
+	return stats
+}
+
+func typeNames(species *pokeapi.GetPokemonResponse) []string {
+	var names []string
+	for _, typ := range species.Types {
+		names = append(names, typ.Type.Name)
+	}
+
+	return names
+}
+
+// gainExperience gives p exp, teaching it the moves of every level reached
+// and raising its HP along with its max HP. It returns what happened, to be
+// shown once the change is saved.
+func gainExperience(ctx *commandContext, p *trainer.Pokemon, exp int) ([]string, error) {
+	species, err := ctx.Client.GetPokemon(p.Species)
+	if err != nil {
+		return nil, fmt.Errorf("error getting pokemon: %w", err)
+	}
+
+	speciesDetails, err := ctx.Client.GetPokemonSpecies(species.Species.Name)
+	if err != nil {
+		return nil, fmt.Errorf("error getting species: %w", err)
+	}
+
+	growth, err := growthRate(ctx, speciesDetails.GrowthRate.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	oldMaxHP := pokemonStats(species, p).HP
+	messages := []string{fmt.Sprintf("%s gained %d experience.", p.Name(), exp)}
+
+	for _, level := range p.GainExperience(exp, growth) {
+		messages = append(messages, fmt.Sprintf("%s grew to level %d!", p.Name(), level))
+
+		for _, name := range movesLearnedAt(species, level) {
+			known := slices.ContainsFunc(p.Moves, func(m trainer.Move) bool {
+				return m.Name == name
+			})
+			if known {
+				continue
+			}
+
+			move, err := ctx.Client.GetMove(name)
+			if err != nil {
+				return nil, fmt.Errorf("error getting move: %w", err)
+			}
+
+			learned := trainer.Move{Name: move.Name, PP: move.PP, MaxPP: move.PP}
+			if len(p.Moves) < maxMoves {
+				p.Moves = append(p.Moves, learned)
+				messages = append(messages, fmt.Sprintf("%s learned %s!", p.Name(), learned.Name))
+				continue
+			}
+
+			forgotten := p.Moves[0].Name
+			p.Moves = append(p.Moves[1:], learned)
+			messages = append(messages, fmt.Sprintf("%s forgot %s and learned %s!", p.Name(), forgotten, learned.Name))
+		}
+	}
+
+	if p.HP > 0 {
+		p.HP += pokemonStats(species, p).HP - oldMaxHP
+	}
+
+	return messages, nil
+}
+
+func growthRate(ctx *commandContext, name string) (trainer.GrowthRate, error) {
+	rate, err := ctx.Client.GetGrowthRate(name)
+	if err != nil {
+		return nil, fmt.Errorf("error getting growth rate: %w", err)
+	}
+
+	levels := slices.Clone(rate.Levels)
+	slices.SortFunc(levels, func(a, b pokeapi.GrowthRateLevel) int {
+		return a.Level - b.Level
+	})
+
+	growth := make(trainer.GrowthRate, len(levels))
+	for i, level := range levels {
+		growth[i] = level.Experience
+	}
+
+	return growth, nil
+}
+
+// maxMoves is how many moves a Pokemon can know at once.
+const maxMoves = 4
+
+// learnLevel returns the level at which a move is learned by leveling up,
+// or false when it is learned some other way.
+func learnLevel(m pokeapi.PokemonMove) (int, bool) {
+	for _, detail := range m.VersionGroupDetails {
+		if detail.MoveLearnMethod.Name == "level-up" {
+			return detail.LevelLearnedAt, true
+		}
+	}
+
+	return 0, false
+}
+
+// movesLearnedAt returns the moves species learns on reaching level.
+func movesLearnedAt(species *pokeapi.GetPokemonResponse, level int) []string {
+	var names []string
+	for _, m := range species.Moves {
+		if learnedAt, ok := learnLevel(m); ok && learnedAt == level {
+			names = append(names, m.Move.Name)
+		}
+	}
+
+	return names
+}
+
+// levelUpMoves returns up to the four most recent moves species learns by
+// leveling up at or below level.
+func levelUpMoves(species *pokeapi.GetPokemonResponse, level int) []string {
+	type learnable struct {
+		name  string
+		level int
+	}
+
+	var moves []learnable
+	for _, m := range species.Moves {
+		if learnedAt, ok := learnLevel(m); ok && learnedAt <= level {
+			moves = append(moves, learnable{name: m.Move.Name, level: learnedAt})
+		}
+	}
+
+	slices.SortStableFunc(moves, func(a, b learnable) int {
+		return a.level - b.level
+	})
+
+	if len(moves) > maxMoves {
+		moves = moves[len(moves)-maxMoves:]
+	}
+
+	var names []string
+	for _, m := range moves {
+		names = append(names, m.name)
+	}
+
+	return names
+}
+
+// encounterLevel rolls the level of a wild Pokemon met in the last explored
+// area, defaulting to level 5 for Pokemon that weren't seen there.
+func encounterLevel(ctx *commandContext, name string) int {
+	for _, e := range ctx.Config.Encounters {
+		if e.Name == name {
+			return e.MinLevel + ctx.Rand.IntN(e.MaxLevel-e.MinLevel+1)
+		}
+	}
+
+	return 5
+}
+
+func valueOrZero(v *int) int {
+	if v == nil {
+		return 0
+	}
+
+	return *v
+}
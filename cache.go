@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ArturM94/pokedexcli/internal/pokecache"
+)
+
+// cachePreviewBytes is how much of a cached body cache get shows.
+const cachePreviewBytes = 500
+
+func commandCache(ctx *commandContext) error {
+	if len(ctx.Args) == 0 {
+		fmt.Println("usage: cache stats|list|clear|get <key>")
+
+		return nil
+	}
+
+	cache := ctx.Client.Cache()
+
+	switch resourceName(ctx.Args[0]) {
+	case "stats":
+		printCacheStats(cache.Stats())
+	case "list":
+		items := cache.Items()
+		if len(items) == 0 {
+			fmt.Println("The cache is empty.")
+
+			return nil
+		}
+
+		now := cache.Now()
+		for _, item := range items {
+			fmt.Printf("%-9s %-22s %s\n", formatBytes(item.Size()), describeFreshness(item.Entry, now), item.Key)
+		}
+	case "clear":
+		fmt.Printf("Removed %d entries from the cache.\n", cache.Clear())
+	case "get":
+		if len(ctx.Args) != 2 {
+			fmt.Println("usage: cache get <key>")
+
+			return nil
+		}
+
+		// Keys are full URLs, but API paths such as pokemon/pikachu
+		// are accepted too.
+		key := ctx.Args[1]
+		entry, ok := cache.Peek(key)
+		if !ok {
+			key = ctx.Client.URL(key)
+			entry, ok = cache.Peek(key)
+		}
+		if !ok {
+			fmt.Println("Not in the cache: " + ctx.Args[1])
+
+			return nil
+		}
+
+		printCacheEntry(key, entry, cache.Now())
+	default:
+		fmt.Println("usage: cache stats|list|clear|get <key>")
+	}
+
+	return nil
+}
+
+func printCacheStats(stats pokecache.Stats) {
+	hitRate := 0.0
+	if lookups := stats.Hits + stats.Misses; lookups > 0 {
+		hitRate = float64(stats.Hits) * 100 / float64(lookups)
+	}
+
+	fmt.Println("Entries:", stats.Entries)
+	fmt.Println("Size:", formatBytes(stats.Bytes))
+	fmt.Printf("Hits: %d (%.1f%%)\n", stats.Hits, hitRate)
+	fmt.Println("Misses:", stats.Misses)
+	fmt.Println("Evictions:", stats.Evictions)
+	fmt.Println("Expired:", stats.Expired)
+}
+
+func printCacheEntry(key string, entry pokecache.Entry, now time.Time) {
+	fmt.Println("Key:", key)
+	fmt.Println("Size:", formatBytes(pokecache.Item{Key: key, Entry: entry}.Size()))
+	fmt.Println("Status:", describeFreshness(entry, now))
+	if entry.ETag != "" {
+		fmt.Println("ETag:", entry.ETag)
+	}
+	if entry.LastModified != "" {
+		fmt.Println("Last-Modified:", entry.LastModified)
+	}
+
+	fmt.Println()
+	if len(entry.Val) <= cachePreviewBytes {
+		fmt.Println(string(entry.Val))
+
+		return
+	}
+
+	fmt.Println(strings.ToValidUTF8(string(entry.Val[:cachePreviewBytes]), ""))
+	fmt.Printf("... (%d more bytes)\n", len(entry.Val)-cachePreviewBytes)
+}
+
+func describeFreshness(entry pokecache.Entry, now time.Time) string {
+	if entry.Fresh(now) {
+		return "fresh for " + entry.ExpiresAt.Sub(now).Round(time.Second).String()
+	}
+
+	return "stale for " + now.Sub(entry.ExpiresAt).Round(time.Second).String()
+}
+
+func formatBytes(n int) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
@@ -0,0 +1,311 @@
+package battle
+
+import (
+	"errors"
+	"math/rand/v2"
+)
+
+var (
+	ErrBattleOver  = errors.New("the battle is over")
+	ErrInvalidMove = errors.New("invalid move")
+	ErrNoPP        = errors.New("no PP left for this move")
+)
+
+const (
+	Physical = "physical"
+	Special  = "special"
+	Status   = "status"
+)
+
+type Stats struct {
+	HP             int `json:"hp"`
+	Attack         int `json:"attack"`
+	Defense        int `json:"defense"`
+	SpecialAttack  int `json:"special_attack"`
+	SpecialDefense int `json:"special_defense"`
+	Speed          int `json:"speed"`
+}
+
+// Nature raises one stat by 10% and lowers another by 10%. Stats are named
+// as in the API, e.g. special-attack. Neutral natures raise and lower the
+// same stat, which cancels out.
+type Nature struct {
+	Name      string `json:"name"`
+	Increased string `json:"increased"`
+	Decreased string `json:"decreased"`
+}
+
+func (n Nature) modify(stat string, value int) int {
+	if n.Increased == n.Decreased {
+		return value
+	}
+
+	switch stat {
+	case n.Increased:
+		return value * 110 / 100
+	case n.Decreased:
+		return value * 90 / 100
+	default:
+		return value
+	}
+}
+
+type Move struct {
+	Name        string
+	Type        string
+	DamageClass string
+	Power       int
+	// Accuracy is a percentage. Zero means the move never misses.
+	Accuracy int
+	PP       int
+	MaxPP    int
+	Priority int
+}
+
+type Pokemon struct {
+	Name  string
+	Level int
+	Types []string
+	// Stats are the actual stats at Level, not the species base stats.
+	Stats Stats
+	HP    int
+	Moves []*Move
+}
+
+func (p *Pokemon) Fainted() bool {
+	return p.HP <= 0
+}
+
+// hasPP reports whether any of the Pokemon's moves can still be used.
+func (p *Pokemon) hasPP() bool {
+	for _, move := range p.Moves {
+		if move.PP > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// struggle is used once a Pokemon has run out of PP for every move.
+var struggle = Move{
+	Name:        "struggle",
+	DamageClass: Physical,
+	Power:       50,
+}
+
+// Attack is what happened when one Pokemon used a move on the other.
+type Attack struct {
+	Attacker      *Pokemon
+	Defender      *Pokemon
+	Move          *Move
+	Missed        bool
+	Critical      bool
+	Effectiveness float64
+	Damage        int
+	Fainted       bool
+}
+
+type Battle struct {
+	Player *Pokemon
+	Wild   *Pokemon
+	Turns  int
+
+	rng *rand.Rand
+}
+
+// New starts a battle. All randomness comes from rng, so two battles with
+// equally seeded generators and the same choices play out identically.
+func New(player, wild *Pokemon, rng *rand.Rand) *Battle {
+	return &Battle{
+		Player: player,
+		Wild:   wild,
+		rng:    rng,
+	}
+}
+
+func (b *Battle) Over() bool {
+	return b.Player.Fainted() || b.Wild.Fainted()
+}
+
+// Winner returns the Pokemon still standing, or nil while the battle goes on.
+func (b *Battle) Winner() *Pokemon {
+	switch {
+	case b.Wild.Fainted():
+		return b.Player
+	case b.Player.Fainted():
+		return b.Wild
+	default:
+		return nil
+	}
+}
+
+// Turn plays one round with the player using the move at moveIndex and the
+// wild Pokemon picking a random move. A Pokemon with no PP left struggles.
+func (b *Battle) Turn(moveIndex int) ([]Attack, error) {
+	if b.Over() {
+		return nil, ErrBattleOver
+	}
+
+	playerMove := &struggle
+	if b.Player.hasPP() {
+		if moveIndex < 0 || moveIndex >= len(b.Player.Moves) {
+			return nil, ErrInvalidMove
+		}
+
+		playerMove = b.Player.Moves[moveIndex]
+		if playerMove.PP == 0 {
+			return nil, ErrNoPP
+		}
+	}
+
+	wildMove := b.chooseMove(b.Wild)
+
+	first, second := b.Player, b.Wild
+	firstMove, secondMove := playerMove, wildMove
+	if b.movesSecond(b.Player, playerMove, b.Wild, wildMove) {
+		first, second = second, first
+		firstMove, secondMove = secondMove, firstMove
+	}
+
+	b.Turns++
+
+	attacks := []Attack{b.attack(first, second, firstMove)}
+	if !second.Fainted() {
+		attacks = append(attacks, b.attack(second, first, secondMove))
+	}
+
+	return attacks, nil
+}
+
+func (b *Battle) chooseMove(p *Pokemon) *Move {
+	var usable []*Move
+	for _, move := range p.Moves {
+		if move.PP > 0 {
+			usable = append(usable, move)
+		}
+	}
+
+	if len(usable) == 0 {
+		return &struggle
+	}
+
+	return usable[b.rng.IntN(len(usable))]
+}
+
+// movesSecond reports whether a loses the race to act first: higher priority
+// goes first, then higher speed, with speed ties decided at random.
+func (b *Battle) movesSecond(a *Pokemon, aMove *Move, d *Pokemon, dMove *Move) bool {
+	if aMove.Priority != dMove.Priority {
+		return aMove.Priority < dMove.Priority
+	}
+
+	if a.Stats.Speed != d.Stats.Speed {
+		return a.Stats.Speed < d.Stats.Speed
+	}
+
+	return b.rng.IntN(2) == 1
+}
+
+func (b *Battle) attack(attacker, defender *Pokemon, move *Move) Attack {
+	if move.PP > 0 {
+		move.PP--
+	}
+
+	result := Attack{
+		Attacker:      attacker,
+		Defender:      defender,
+		Move:          move,
+		Effectiveness: 1,
+	}
+
+	if move.Accuracy > 0 && b.rng.IntN(100) >= move.Accuracy {
+		result.Missed = true
+
+		return result
+	}
+
+	if move.DamageClass == Status || move.Power == 0 {
+		return result
+	}
+
+	result.Critical = b.rng.IntN(24) == 0
+	random := 85 + b.rng.IntN(16)
+
+	result.Damage, result.Effectiveness = Damage(attacker, defender, move, result.Critical, random)
+
+	defender.HP -= result.Damage
+	if defender.HP < 0 {
+		defender.HP = 0
+	}
+	result.Fainted = defender.Fainted()
+
+	return result
+}
+
+// Damage applies the damage formula. random is the percentage roll between
+// 85 and 100. It also returns the type effectiveness that was applied.
+func Damage(attacker, defender *Pokemon, move *Move, critical bool, random int) (int, float64) {
+	attack, defense := attacker.Stats.Attack, defender.Stats.Defense
+	if move.DamageClass == Special {
+		attack, defense = attacker.Stats.SpecialAttack, defender.Stats.SpecialDefense
+	}
+
+	if defense < 1 {
+		defense = 1
+	}
+
+	damage := (2*attacker.Level/5+2)*move.Power*attack/defense/50 + 2
+
+	if critical {
+		damage = damage * 3 / 2
+	}
+
+	damage = damage * random / 100
+
+	for _, t := range attacker.Types {
+		if t == move.Type {
+			damage = damage * 3 / 2
+			break
+		}
+	}
+
+	effectiveness := 1.0
+	if move.Type != "" {
+		effectiveness = Effectiveness(move.Type, defender.Types)
+	}
+
+	if effectiveness == 0 {
+		return 0, effectiveness
+	}
+
+	damage = int(float64(damage) * effectiveness)
+	if damage < 1 {
+		damage = 1
+	}
+
+	return damage, effectiveness
+}
+
+// CalcStats returns the stats of a Pokemon at level from its species base
+// stats, individual values, effort values and nature.
+func CalcStats(base, ivs, evs Stats, level int, nature Nature) Stats {
+	stat := func(name string, b, iv, ev int) int {
+		return nature.modify(name, (2*b+iv+ev/4)*level/100+5)
+	}
+
+	return Stats{
+		HP:             (2*base.HP+ivs.HP+evs.HP/4)*level/100 + level + 10,
+		Attack:         stat("attack", base.Attack, ivs.Attack, evs.Attack),
+		Defense:        stat("defense", base.Defense, ivs.Defense, evs.Defense),
+		SpecialAttack:  stat("special-attack", base.SpecialAttack, ivs.SpecialAttack, evs.SpecialAttack),
+		SpecialDefense: stat("special-defense", base.SpecialDefense, ivs.SpecialDefense, evs.SpecialDefense),
+		Speed:          stat("speed", base.Speed, ivs.Speed, evs.Speed),
+	}
+}
+
+// ExperienceYield is the experience earned for defeating a wild Pokemon with
+// the given species base experience at level.
+func ExperienceYield(baseExperience, level int) int {
+	return max(1, baseExperience*level/7)
+}
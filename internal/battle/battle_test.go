@@ -0,0 +1,210 @@
+package battle
+
+import (
+	"math/rand/v2"
+	"testing"
+)
+
+func newPokemon(name string, types []string, speed int) *Pokemon {
+	stats := Stats{HP: 100, Attack: 100, Defense: 100, SpecialAttack: 100, SpecialDefense: 100, Speed: speed}
+
+	return &Pokemon{
+		Name:  name,
+		Level: 50,
+		Types: types,
+		Stats: stats,
+		HP:    stats.HP,
+		Moves: []*Move{
+			{Name: "tackle", Type: "normal", DamageClass: Physical, Power: 40, Accuracy: 100, PP: 35, MaxPP: 35},
+			{Name: "ember", Type: "fire", DamageClass: Special, Power: 40, Accuracy: 100, PP: 25, MaxPP: 25},
+		},
+	}
+}
+
+func TestDamage(t *testing.T) {
+	move := &Move{Name: "strength", Type: "normal", DamageClass: Physical, Power: 80}
+
+	cases := []struct {
+		attackerTypes []string
+		defenderTypes []string
+		critical      bool
+		random        int
+		expected      int
+	}{
+		{attackerTypes: []string{"water"}, defenderTypes: []string{"water"}, random: 100, expected: 37},
+		{attackerTypes: []string{"water"}, defenderTypes: []string{"water"}, random: 85, expected: 31},
+		{attackerTypes: []string{"water"}, defenderTypes: []string{"water"}, critical: true, random: 100, expected: 55},
+		{attackerTypes: []string{"normal"}, defenderTypes: []string{"water"}, random: 100, expected: 55},
+		{attackerTypes: []string{"water"}, defenderTypes: []string{"rock"}, random: 100, expected: 18},
+		{attackerTypes: []string{"water"}, defenderTypes: []string{"ghost"}, random: 100, expected: 0},
+	}
+
+	for _, c := range cases {
+		attacker := newPokemon("attacker", c.attackerTypes, 50)
+		defender := newPokemon("defender", c.defenderTypes, 50)
+
+		damage, _ := Damage(attacker, defender, move, c.critical, c.random)
+		if damage != c.expected {
+			t.Errorf("Damage(%v vs %v, critical %v, random %d) == %d, want %d",
+				c.attackerTypes, c.defenderTypes, c.critical, c.random, damage, c.expected)
+		}
+	}
+}
+
+func TestEffectiveness(t *testing.T) {
+	cases := []struct {
+		attackType    string
+		defenderTypes []string
+		expected      float64
+	}{
+		{attackType: "water", defenderTypes: []string{"fire"}, expected: 2},
+		{attackType: "electric", defenderTypes: []string{"water", "flying"}, expected: 4},
+		{attackType: "grass", defenderTypes: []string{"fire", "flying"}, expected: 0.25},
+		{attackType: "ground", defenderTypes: []string{"flying"}, expected: 0},
+		{attackType: "normal", defenderTypes: []string{"normal"}, expected: 1},
+	}
+
+	for _, c := range cases {
+		if actual := Effectiveness(c.attackType, c.defenderTypes); actual != c.expected {
+			t.Errorf("Effectiveness(%s, %v) == %v, want %v", c.attackType, c.defenderTypes, actual, c.expected)
+		}
+	}
+}
+
+func TestTurnOrder(t *testing.T) {
+	player := newPokemon("slow", []string{"normal"}, 10)
+	wild := newPokemon("fast", []string{"normal"}, 90)
+	player.Moves = append(player.Moves, &Move{Name: "quick-attack", Type: "normal", DamageClass: Physical, Power: 40, Accuracy: 100, PP: 30, MaxPP: 30, Priority: 1})
+
+	b := New(player, wild, rand.New(rand.NewPCG(1, 2)))
+
+	attacks, err := b.Turn(0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if attacks[0].Attacker != wild {
+		t.Errorf("expected the faster Pokemon to move first")
+	}
+
+	attacks, err = b.Turn(2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if attacks[0].Attacker != player {
+		t.Errorf("expected the higher priority move to go first")
+	}
+}
+
+func TestTurnUsesPP(t *testing.T) {
+	player := newPokemon("player", []string{"normal"}, 50)
+	wild := newPokemon("wild", []string{"normal"}, 50)
+	player.Moves[0].PP = 1
+
+	b := New(player, wild, rand.New(rand.NewPCG(1, 2)))
+
+	if _, err := b.Turn(0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if player.Moves[0].PP != 0 {
+		t.Errorf("expected PP to drop to 0, got %d", player.Moves[0].PP)
+	}
+
+	if _, err := b.Turn(0); err != ErrNoPP {
+		t.Errorf("expected ErrNoPP, got %v", err)
+	}
+
+	if _, err := b.Turn(5); err != ErrInvalidMove {
+		t.Errorf("expected ErrInvalidMove, got %v", err)
+	}
+}
+
+func TestBattleSameSeed(t *testing.T) {
+	play := func() []int {
+		player := newPokemon("player", []string{"fire"}, 50)
+		wild := newPokemon("wild", []string{"grass"}, 50)
+		b := New(player, wild, rand.New(rand.NewPCG(7, 7)))
+
+		var damages []int
+		for !b.Over() {
+			attacks, err := b.Turn(1)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			for _, a := range attacks {
+				damages = append(damages, a.Damage)
+			}
+		}
+
+		return damages
+	}
+
+	first, second := play(), play()
+	if len(first) != len(second) {
+		t.Fatalf("expected the same number of attacks, got %d and %d", len(first), len(second))
+	}
+
+	for i := range first {
+		if first[i] != second[i] {
+			t.Errorf("attack %d dealt %d and %d with the same seed", i, first[i], second[i])
+		}
+	}
+}
+
+func TestCalcStats(t *testing.T) {
+	// Garchomp's base stats.
+	base := Stats{HP: 108, Attack: 130, Defense: 95, SpecialAttack: 80, SpecialDefense: 85, Speed: 102}
+
+	cases := []struct {
+		ivs      Stats
+		evs      Stats
+		level    int
+		nature   Nature
+		expected Stats
+	}{
+		{
+			level:    100,
+			expected: Stats{HP: 326, Attack: 265, Defense: 195, SpecialAttack: 165, SpecialDefense: 175, Speed: 209},
+		},
+		{
+			ivs:      Stats{HP: 24, Attack: 12, Defense: 30, SpecialAttack: 16, SpecialDefense: 23, Speed: 5},
+			evs:      Stats{HP: 74, Attack: 190, Defense: 91, SpecialAttack: 48, SpecialDefense: 84, Speed: 23},
+			level:    78,
+			nature:   Nature{Name: "adamant", Increased: "attack", Decreased: "special-attack"},
+			expected: Stats{HP: 289, Attack: 278, Defense: 193, SpecialAttack: 135, SpecialDefense: 171, Speed: 171},
+		},
+		{
+			level:    100,
+			nature:   Nature{Name: "hardy", Increased: "attack", Decreased: "attack"},
+			expected: Stats{HP: 326, Attack: 265, Defense: 195, SpecialAttack: 165, SpecialDefense: 175, Speed: 209},
+		},
+	}
+
+	for _, c := range cases {
+		actual := CalcStats(base, c.ivs, c.evs, c.level, c.nature)
+		if actual != c.expected {
+			t.Errorf("CalcStats(level %d, %s) == %+v, want %+v", c.level, c.nature.Name, actual, c.expected)
+		}
+	}
+}
+
+func TestExperienceYield(t *testing.T) {
+	cases := []struct {
+		baseExperience int
+		level          int
+		expected       int
+	}{
+		{baseExperience: 50, level: 3, expected: 21},
+		{baseExperience: 64, level: 10, expected: 91},
+		{baseExperience: 0, level: 2, expected: 1},
+	}
+
+	for _, c := range cases {
+		if actual := ExperienceYield(c.baseExperience, c.level); actual != c.expected {
+			t.Errorf("ExperienceYield(%d, %d) == %d, want %d", c.baseExperience, c.level, actual, c.expected)
+		}
+	}
+}
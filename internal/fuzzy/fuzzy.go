@@ -0,0 +1,93 @@
+package fuzzy
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+type Match struct {
+	Name     string
+	Distance int
+}
+
+// Distance is the number of single character insertions, deletions,
+// substitutions and swaps of adjacent characters that turn a into b.
+func Distance(a, b string) int {
+	s, t := []rune(a), []rune(b)
+
+	// rows[i%3] holds the distances from s[:i] to every prefix of t.
+	rows := [3][]int{}
+	for i := range rows {
+		rows[i] = make([]int, len(t)+1)
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+
+	for i := 1; i <= len(s); i++ {
+		prev2, prev, cur := rows[(i+1)%3], rows[(i-1)%3], rows[i%3]
+		cur[0] = i
+
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+	}
+
+	return rows[len(s)%3][len(t)]
+}
+
+// MaxDistance is how far a name may be from query to still count as a
+// match: one edit for every three characters, and at least one.
+func MaxDistance(query string) int {
+	return max(1, utf8.RuneCountInString(query)/3)
+}
+
+// Closest returns up to limit candidates that are within MaxDistance of
+// query or start with it, closest first.
+func Closest(query string, candidates []string, limit int) []Match {
+	maxDistance := MaxDistance(query)
+
+	var matches []Match
+	for _, candidate := range candidates {
+		d := Distance(query, candidate)
+		if d <= maxDistance || strings.HasPrefix(candidate, query) {
+			matches = append(matches, Match{Name: candidate, Distance: d})
+		}
+	}
+
+	slices.SortFunc(matches, func(a, b Match) int {
+		if c := cmp.Compare(a.Distance, b.Distance); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	return matches
+}
+
+// Clear reports whether the first of matches is a clear winner: it is the
+// only match or strictly closer than the runner-up.
+func Clear(matches []Match) bool {
+	switch len(matches) {
+	case 0:
+		return false
+	case 1:
+		return true
+	default:
+		return matches[0].Distance < matches[1].Distance
+	}
+}
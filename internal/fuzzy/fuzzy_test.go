@@ -0,0 +1,65 @@
+package fuzzy
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDistance(t *testing.T) {
+	cases := []struct {
+		a        string
+		b        string
+		expected int
+	}{
+		{a: "pikachu", b: "pikachu", expected: 0},
+		{a: "pikachoo", b: "pikachu", expected: 2},
+		{a: "pikahcu", b: "pikachu", expected: 1},
+		{a: "", b: "abra", expected: 4},
+		{a: "kadabra", b: "abra", expected: 3},
+		{a: "flabébé", b: "flabebe", expected: 2},
+	}
+
+	for _, c := range cases {
+		if actual := Distance(c.a, c.b); actual != c.expected {
+			t.Errorf("Distance(%q, %q) == %d, want %d", c.a, c.b, actual, c.expected)
+		}
+
+		if actual := Distance(c.b, c.a); actual != c.expected {
+			t.Errorf("Distance(%q, %q) == %d, want %d", c.b, c.a, actual, c.expected)
+		}
+	}
+}
+
+func TestClosest(t *testing.T) {
+	candidates := []string{"pikachu", "raichu", "pichu", "canalave-city-area", "charmander", "charmeleon"}
+
+	cases := []struct {
+		query    string
+		expected []string
+		clear    bool
+	}{
+		{query: "pikachoo", expected: []string{"pikachu"}, clear: true},
+		{query: "canalave-city", expected: []string{"canalave-city-area"}, clear: true},
+		{query: "charmelon", expected: []string{"charmeleon"}, clear: true},
+		{query: "pichu", expected: []string{"pichu"}, clear: true},
+		{query: "charm", expected: []string{"charmander", "charmeleon"}, clear: false},
+		{query: "mewtwo", expected: nil, clear: false},
+	}
+
+	for _, c := range cases {
+		matches := Closest(c.query, candidates, 3)
+
+		var names []string
+		for _, m := range matches {
+			names = append(names, m.Name)
+		}
+
+		if !slices.Equal(names, c.expected) {
+			t.Errorf("Closest(%q) == %q, want %q", c.query, names, c.expected)
+		}
+
+		if actual := Clear(matches); actual != c.clear {
+			t.Errorf("Clear(Closest(%q)) == %v, want %v", c.query, actual, c.clear)
+		}
+	}
+}
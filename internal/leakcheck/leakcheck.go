@@ -0,0 +1,43 @@
+package leakcheck
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// VerifyTestMain runs the tests and fails them if goroutines started during
+// the run are still around a second after they pass. It's meant to be called
+// from TestMain and doesn't return.
+func VerifyTestMain(m *testing.M) {
+	before := runtime.NumGoroutine()
+
+	code := m.Run()
+
+	// Idle keep-alive connections have goroutines of their own.
+	http.DefaultClient.CloseIdleConnections()
+	if code == 0 && !Settle(before, time.Second) {
+		buf := make([]byte, 1<<20)
+		fmt.Fprintf(os.Stderr, "leaked goroutines: %d before, %d after\n%s\n",
+			before, runtime.NumGoroutine(), buf[:runtime.Stack(buf, true)])
+		code = 1
+	}
+
+	os.Exit(code)
+}
+
+// Settle waits up to timeout for the number of goroutines to drop back to n.
+func Settle(n int, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for runtime.NumGoroutine() > n {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	return true
+}
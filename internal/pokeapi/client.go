@@ -0,0 +1,399 @@
+package pokeapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ArturM94/pokedexcli/internal/pokecache"
+)
+
+const DefaultBaseURL = "https://pokeapi.co/api/v2"
+
+// DefaultTimeout is how long a request may take before it fails, so a hung
+// API falls back to stale data instead of blocking forever.
+const DefaultTimeout = 10 * time.Second
+
+// listPageSize is how many resources List fetches at a time.
+const listPageSize = 200
+
+// Client fetches resources from the PokeAPI, keeping responses in a cache.
+// It is safe for concurrent use: callers asking for the same URL at the same
+// time share a single request.
+type Client struct {
+	cache      *pokecache.Cache
+	baseURL    string
+	httpClient *http.Client
+
+	// OnStale is called when an expired response is used because fetching
+	// a fresh one failed, with how long ago it expired and why the fetch
+	// failed. It isn't called for fetches made in the background, such as
+	// by PrefetchPokemon, so nothing is printed over the prompt.
+	OnStale func(url string, staleFor time.Duration, err error)
+
+	// Timeout limits how long each request may take. Zero means no limit.
+	Timeout time.Duration
+
+	mu    sync.Mutex
+	calls map[string]*call
+	// failures is the error of the last request for each URL, until one
+	// succeeds.
+	failures map[string]error
+}
+
+// call is a request in flight, shared by everyone waiting for its URL.
+type call struct {
+	done    chan struct{}
+	data    []byte
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// NewClient returns a client for the API at baseURL, normally
+// DefaultBaseURL.
+func NewClient(cache *pokecache.Cache, baseURL string) *Client {
+	return &Client{
+		cache:      cache,
+		baseURL:    baseURL,
+		httpClient: http.DefaultClient,
+		Timeout:    DefaultTimeout,
+		calls:      map[string]*call{},
+		failures:   map[string]error{},
+	}
+}
+
+// Cache is the cache the client keeps responses in.
+func (c *Client) Cache() *pokecache.Cache {
+	return c.cache
+}
+
+// URL is the full URL of an API path such as pokemon/pikachu.
+func (c *Client) URL(path string) string {
+	return c.baseURL + "/" + strings.TrimPrefix(path, "/")
+}
+
+// List iterates over every resource of an endpoint such as pokemon or move.
+// Pages are fetched as the loop reaches them, so breaking out early skips
+// the rest. A failed page, including one cancelled through ctx, ends the
+// iteration with the error.
+func (c *Client) List(ctx context.Context, endpoint string) iter.Seq2[NamedAPIResource, error] {
+	return func(yield func(NamedAPIResource, error) bool) {
+		next := c.baseURL + "/" + endpoint + "?limit=" + strconv.Itoa(listPageSize)
+
+		for next != "" {
+			var page NamedAPIResourceList
+			if err := c.get(ctx, next, &page); err != nil {
+				yield(NamedAPIResource{}, fmt.Errorf("error listing %s: %w", endpoint, err))
+				return
+			}
+
+			for _, r := range page.Results {
+				if !yield(r, nil) {
+					return
+				}
+			}
+
+			next = ""
+			if page.Next != nil {
+				next = *page.Next
+			}
+		}
+	}
+}
+
+func (c *Client) get(ctx context.Context, fullURL string, v any) error {
+	data, err := c.fetch(ctx, fullURL)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("error parsing response: %w", err)
+	}
+
+	return nil
+}
+
+// fetch returns the body at fullURL, from the cache when it is there. A
+// value that expired only recently is returned at once while it is
+// refreshed in the background, and an older one is returned when refreshing
+// it fails. Either way OnStale is called, unless ctx is a background one,
+// when the value is served because the last refresh failed.
+func (c *Client) fetch(ctx context.Context, fullURL string) ([]byte, error) {
+	if data, ok := c.cache.Get(fullURL); ok {
+		return data, nil
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	stale, hasStale := c.cache.Lookup(fullURL)
+	staleFor := c.cache.Now().Sub(stale.ExpiresAt)
+	if hasStale && staleFor <= c.cache.StaleWhileRevalidate() {
+		c.mu.Lock()
+		failure := c.failures[fullURL]
+		c.mu.Unlock()
+
+		if failure != nil && c.OnStale != nil && !isBackground(ctx) {
+			c.OnStale(fullURL, staleFor, failure)
+		}
+		c.refresh(fullURL)
+
+		return stale.Val, nil
+	}
+
+	data, err := c.wait(ctx, fullURL)
+	if err != nil && hasStale && ctx.Err() == nil {
+		if c.OnStale != nil && !isBackground(ctx) {
+			c.OnStale(fullURL, staleFor, err)
+		}
+
+		return stale.Val, nil
+	}
+
+	return data, err
+}
+
+// backgroundKey marks a context used for work nobody is waiting on, whose
+// problems shouldn't be reported to the user.
+type backgroundKey struct{}
+
+func isBackground(ctx context.Context) bool {
+	background, _ := ctx.Value(backgroundKey{}).(bool)
+	return background
+}
+
+// wait joins the request in flight for fullURL, starting one if there is
+// none, and waits for its result. The request is only cancelled once every
+// caller waiting for it has given up.
+func (c *Client) wait(ctx context.Context, fullURL string) ([]byte, error) {
+	c.mu.Lock()
+	cl, ok := c.calls[fullURL]
+	if !ok {
+		cl = c.start(context.WithoutCancel(ctx), fullURL)
+	}
+	cl.waiters++
+	c.mu.Unlock()
+
+	select {
+	case <-cl.done:
+		return cl.data, cl.err
+	case <-ctx.Done():
+		c.mu.Lock()
+		cl.waiters--
+		if cl.waiters == 0 {
+			cl.cancel()
+			if c.calls[fullURL] == cl {
+				delete(c.calls, fullURL)
+			}
+		}
+		c.mu.Unlock()
+
+		return nil, ctx.Err()
+	}
+}
+
+// refresh fetches fullURL into the cache in the background, unless a
+// request for it is already in flight.
+func (c *Client) refresh(fullURL string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.calls[fullURL]; !ok {
+		c.start(context.Background(), fullURL)
+	}
+}
+
+// start makes a request for fullURL that callers can wait on. c.mu must be
+// held.
+func (c *Client) start(ctx context.Context, fullURL string) *call {
+	var requestCtx context.Context
+	var cancel context.CancelFunc
+	if c.Timeout > 0 {
+		requestCtx, cancel = context.WithTimeout(ctx, c.Timeout)
+	} else {
+		requestCtx, cancel = context.WithCancel(ctx)
+	}
+	cl := &call{done: make(chan struct{}), cancel: cancel}
+	c.calls[fullURL] = cl
+
+	go c.do(requestCtx, fullURL, cl)
+
+	return cl
+}
+
+// do makes the request for cl and hands the result to its waiters.
+func (c *Client) do(ctx context.Context, fullURL string, cl *call) {
+	defer cl.cancel()
+
+	cl.data, cl.err = c.request(ctx, fullURL)
+
+	c.mu.Lock()
+	if c.calls[fullURL] == cl {
+		delete(c.calls, fullURL)
+	}
+	switch {
+	case cl.err == nil:
+		delete(c.failures, fullURL)
+	case !errors.Is(cl.err, context.Canceled):
+		// A request cancelled by its callers says nothing about the API.
+		c.failures[fullURL] = cl.err
+	}
+	c.mu.Unlock()
+
+	close(cl.done)
+}
+
+// request fetches fullURL and caches the response for as long as its
+// Cache-Control header allows. An expired entry that has a validator is
+// revalidated with a conditional request, reusing the cached body when the
+// server answers 304 Not Modified.
+func (c *Client) request(ctx context.Context, fullURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fullURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	stale, hasStale := c.cache.Lookup(fullURL)
+	if hasStale {
+		if stale.ETag != "" {
+			req.Header.Set("If-None-Match", stale.ETag)
+		}
+		if stale.LastModified != "" {
+			req.Header.Set("If-Modified-Since", stale.LastModified)
+		}
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	defer res.Body.Close()
+
+	ttl, store := cacheTTL(res.Header, c.cache.TTL())
+
+	if res.StatusCode == http.StatusNotModified && hasStale {
+		// A 304 may carry new validators for the same body.
+		if etag := res.Header.Get("ETag"); etag != "" {
+			stale.ETag = etag
+		}
+		if lastModified := res.Header.Get("Last-Modified"); lastModified != "" {
+			stale.LastModified = lastModified
+		}
+		stale.ExpiresAt = c.cache.Now().Add(ttl)
+		c.cache.AddEntry(fullURL, stale)
+
+		return stale.Val, nil
+	}
+
+	if res.StatusCode > 299 {
+		return nil, fmt.Errorf("error getting request: status code is %d", res.StatusCode)
+	}
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response: %w", err)
+	}
+
+	if store {
+		c.cache.AddEntry(fullURL, pokecache.Entry{
+			Val:          data,
+			ETag:         res.Header.Get("ETag"),
+			LastModified: res.Header.Get("Last-Modified"),
+			ExpiresAt:    c.cache.Now().Add(ttl),
+		})
+	}
+
+	return data, nil
+}
+
+// cacheTTL reads how long a response may be cached from its Cache-Control
+// and Age headers, falling back to fallback when they don't say. store is
+// false when the response must not be cached at all.
+func cacheTTL(header http.Header, fallback time.Duration) (ttl time.Duration, store bool) {
+	ttl = fallback
+
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		name, value, _ := strings.Cut(strings.ToLower(strings.TrimSpace(directive)), "=")
+
+		switch name {
+		case "no-store":
+			return 0, false
+		case "no-cache":
+			return 0, true
+		case "max-age":
+			seconds, err := strconv.Atoi(strings.Trim(value, `"`))
+			if err != nil || seconds < 0 {
+				continue
+			}
+
+			ttl = time.Duration(seconds) * time.Second
+			if age, err := strconv.Atoi(header.Get("Age")); err == nil && age > 0 {
+				ttl = max(0, ttl-time.Duration(age)*time.Second)
+			}
+		}
+	}
+
+	return ttl, true
+}
+
+// PrefetchPokemon fetches the named Pokemon and their species into the cache
+// in the background, with at most workers requests in flight. Failures are
+// ignored: the data is fetched again when it's needed. Cancelling ctx stops
+// the remaining work. The returned channel is closed once it's all done.
+func (c *Client) PrefetchPokemon(ctx context.Context, names []string, workers int) <-chan struct{} {
+	ctx = context.WithValue(ctx, backgroundKey{}, true)
+	jobs := make(chan string)
+	done := make(chan struct{})
+
+	var wg sync.WaitGroup
+	for range max(1, min(workers, len(names))) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for name := range jobs {
+				c.prefetchPokemon(ctx, name)
+			}
+		}()
+	}
+
+	go func() {
+		defer close(done)
+
+	feed:
+		for _, name := range names {
+			select {
+			case jobs <- name:
+			case <-ctx.Done():
+				break feed
+			}
+		}
+
+		close(jobs)
+		wg.Wait()
+	}()
+
+	return done
+}
+
+func (c *Client) prefetchPokemon(ctx context.Context, name string) {
+	var pokemon GetPokemonResponse
+	if err := c.get(ctx, c.baseURL+"/pokemon/"+name, &pokemon); err != nil {
+		return
+	}
+
+	// Species are looked up by name, so this is the URL GetPokemonSpecies
+	// will ask the cache for.
+	c.fetch(ctx, c.baseURL+"/pokemon-species/"+pokemon.Species.Name)
+}
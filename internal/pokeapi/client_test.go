@@ -0,0 +1,718 @@
+package pokeapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ArturM94/pokedexcli/internal/leakcheck"
+	"github.com/ArturM94/pokedexcli/internal/pokecache"
+)
+
+// TestMain fails the tests when they leave goroutines running, such as a
+// request that outlived its callers or a cache that wasn't closed.
+func TestMain(m *testing.M) {
+	leakcheck.VerifyTestMain(m)
+}
+
+// newTestCache returns a cache that is closed when the test ends.
+func newTestCache(t *testing.T, opts ...pokecache.Option) *pokecache.Cache {
+	t.Helper()
+
+	cache := pokecache.NewCache(time.Minute, opts...)
+	t.Cleanup(cache.Close)
+
+	return cache
+}
+
+// newListServer serves /pokemon as a list of total resources, pageSize at a
+// time, counting the requests it gets.
+func newListServer(t *testing.T, total, pageSize int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var requests atomic.Int32
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+
+		page := NamedAPIResourceList{Count: total}
+		for i := offset; i < min(offset+pageSize, total); i++ {
+			page.Results = append(page.Results, NamedAPIResource{
+				Name: fmt.Sprintf("pokemon-%d", i+1),
+				URL:  fmt.Sprintf("%s/pokemon/%d/", server.URL, i+1),
+			})
+		}
+
+		if offset+pageSize < total {
+			next := fmt.Sprintf("%s/pokemon?offset=%d&limit=%d", server.URL, offset+pageSize, pageSize)
+			page.Next = &next
+		}
+
+		json.NewEncoder(w).Encode(page)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &requests
+}
+
+func TestList(t *testing.T) {
+	server, requests := newListServer(t, 5, 2)
+	client := NewClient(newTestCache(t), server.URL)
+
+	var names []string
+	for r, err := range client.List(context.Background(), "pokemon") {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		names = append(names, r.Name)
+	}
+
+	expected := []string{"pokemon-1", "pokemon-2", "pokemon-3", "pokemon-4", "pokemon-5"}
+	if !slices.Equal(names, expected) {
+		t.Errorf("List(pokemon) == %q, want %q", names, expected)
+	}
+
+	if n := requests.Load(); n != 3 {
+		t.Errorf("expected 3 page requests, got %d", n)
+	}
+
+	for _, err := range client.List(context.Background(), "pokemon") {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if n := requests.Load(); n != 3 {
+		t.Errorf("expected listing again to be served from the cache, got %d requests", n)
+	}
+}
+
+func TestListStopsEarly(t *testing.T) {
+	server, requests := newListServer(t, 5, 2)
+	client := NewClient(newTestCache(t), server.URL)
+
+	for r, err := range client.List(context.Background(), "pokemon") {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if r.Name == "pokemon-1" {
+			break
+		}
+	}
+
+	if n := requests.Load(); n != 1 {
+		t.Errorf("expected only the first page to be fetched, got %d requests", n)
+	}
+}
+
+func TestListCancelled(t *testing.T) {
+	server, _ := newListServer(t, 5, 2)
+	client := NewClient(newTestCache(t), server.URL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var got int
+	var listErr error
+	for _, err := range client.List(ctx, "pokemon") {
+		if err != nil {
+			listErr = err
+			break
+		}
+
+		got++
+		cancel()
+	}
+
+	if got != 2 {
+		t.Errorf("expected the first page of 2 before cancelling, got %d", got)
+	}
+
+	if !errors.Is(listErr, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", listErr)
+	}
+}
+
+// newPokemonServer serves /pokemon/{name} and /pokemon-species/{name},
+// recording the paths requested and the most requests it handled at once.
+func newPokemonServer(t *testing.T) (*httptest.Server, func() ([]string, int)) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var paths []string
+	var inFlight, maxInFlight int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		name := path.Base(r.URL.Path)
+		fmt.Fprintf(w, `{"name": %q, "species": {"name": %q}}`, name, name)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() ([]string, int) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return slices.Clone(paths), maxInFlight
+	}
+}
+
+func TestPrefetchPokemon(t *testing.T) {
+	server, stats := newPokemonServer(t)
+	client := NewClient(newTestCache(t), server.URL)
+
+	names := []string{"pidgey", "rattata", "spearow", "zubat", "geodude"}
+	<-client.PrefetchPokemon(context.Background(), names, 2)
+
+	paths, maxInFlight := stats()
+	if len(paths) != 2*len(names) {
+		t.Errorf("expected %d requests, got %d: %v", 2*len(names), len(paths), paths)
+	}
+
+	if maxInFlight > 2 {
+		t.Errorf("expected at most 2 requests at once, got %d", maxInFlight)
+	}
+
+	for _, name := range names {
+		if _, err := client.GetPokemon(name); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if _, err := client.GetPokemonSpecies(name); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if paths, _ := stats(); len(paths) != 2*len(names) {
+		t.Errorf("expected prefetched data to come from the cache, got %d requests", len(paths))
+	}
+}
+
+func TestPrefetchPokemonCancelled(t *testing.T) {
+	server, stats := newPokemonServer(t)
+	client := NewClient(newTestCache(t), server.URL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	select {
+	case <-client.PrefetchPokemon(ctx, []string{"pidgey", "rattata", "spearow"}, 1):
+	case <-time.After(time.Second):
+		t.Fatalf("expected a cancelled prefetch to stop")
+	}
+
+	if paths, _ := stats(); len(paths) > 1 {
+		t.Errorf("expected a cancelled prefetch to stop fetching, got %v", paths)
+	}
+}
+
+// waitForRequests blocks until client has no requests in flight.
+func waitForRequests(t *testing.T, client *Client) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		client.mu.Lock()
+		n := len(client.calls)
+		client.mu.Unlock()
+
+		if n == 0 {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	t.Fatalf("expected the requests in flight to finish")
+}
+
+// waitForWaiters blocks until n callers share the request for fullURL.
+func waitForWaiters(t *testing.T, client *Client, fullURL string, n int) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		client.mu.Lock()
+		cl := client.calls[fullURL]
+		joined := cl != nil && cl.waiters == n
+		client.mu.Unlock()
+
+		if joined {
+			return
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	t.Fatalf("expected %d callers to wait for %s", n, fullURL)
+}
+
+// newBlockingServer answers every request once release is closed.
+func newBlockingServer(t *testing.T) (*httptest.Server, *atomic.Int32, chan struct{}) {
+	t.Helper()
+
+	var requests atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		<-release
+		fmt.Fprint(w, `{"name": "pikachu"}`)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &requests, release
+}
+
+func TestFetchCoalesces(t *testing.T) {
+	server, requests, release := newBlockingServer(t)
+	client := NewClient(newTestCache(t), server.URL)
+
+	const callers = 10
+
+	var wg sync.WaitGroup
+	errs := make(chan error, callers)
+	for range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			pokemon, err := client.GetPokemon("pikachu")
+			if err == nil && pokemon.Name != "pikachu" {
+				err = fmt.Errorf("got %q", pokemon.Name)
+			}
+			errs <- err
+		}()
+	}
+
+	waitForWaiters(t, client, server.URL+"/pokemon/pikachu", callers)
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+
+	if n := requests.Load(); n != 1 {
+		t.Errorf("expected 1 request for %d concurrent callers, got %d", callers, n)
+	}
+}
+
+func TestFetchCancelledCallerLeavesOthers(t *testing.T) {
+	server, requests, release := newBlockingServer(t)
+	client := NewClient(newTestCache(t), server.URL)
+	fullURL := server.URL + "/pokemon/pikachu"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cancelled := make(chan error, 1)
+	go func() {
+		_, err := client.fetch(ctx, fullURL)
+		cancelled <- err
+	}()
+	waitForWaiters(t, client, fullURL, 1)
+
+	waiting := make(chan error, 1)
+	go func() {
+		_, err := client.fetch(context.Background(), fullURL)
+		waiting <- err
+	}()
+	waitForWaiters(t, client, fullURL, 2)
+
+	cancel()
+	if err := <-cancelled; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	close(release)
+	if err := <-waiting; err != nil {
+		t.Errorf("expected the other caller to get the response, got %v", err)
+	}
+
+	if n := requests.Load(); n != 1 {
+		t.Errorf("expected 1 request, got %d", n)
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	const fallback = time.Minute
+
+	cases := []struct {
+		cacheControl string
+		age          string
+		ttl          time.Duration
+		store        bool
+	}{
+		{cacheControl: "", ttl: fallback, store: true},
+		{cacheControl: "public, max-age=86400, s-maxage=86400", ttl: 24 * time.Hour, store: true},
+		{cacheControl: "max-age=600", age: "100", ttl: 500 * time.Second, store: true},
+		{cacheControl: "max-age=60", age: "120", ttl: 0, store: true},
+		{cacheControl: "Max-Age=\"30\"", ttl: 30 * time.Second, store: true},
+		{cacheControl: "max-age=soon", ttl: fallback, store: true},
+		{cacheControl: "no-cache", ttl: 0, store: true},
+		{cacheControl: "private, no-store", ttl: 0, store: false},
+	}
+
+	for _, c := range cases {
+		header := http.Header{}
+		header.Set("Cache-Control", c.cacheControl)
+		if c.age != "" {
+			header.Set("Age", c.age)
+		}
+
+		ttl, store := cacheTTL(header, fallback)
+		if ttl != c.ttl || store != c.store {
+			t.Errorf("cacheTTL(%q, age %q) == %v, %v, want %v, %v", c.cacheControl, c.age, ttl, store, c.ttl, c.store)
+		}
+	}
+}
+
+func TestRevalidate(t *testing.T) {
+	var requests, notModified atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		w.Header().Set("Cache-Control", "max-age=0")
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", "Mon, 19 Oct 2026 10:00:00 GMT")
+
+		if r.Header.Get("If-None-Match") == `"v1"` && r.Header.Get("If-Modified-Since") != "" {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		fmt.Fprint(w, `{"name": "pikachu"}`)
+	}))
+	defer server.Close()
+
+	client := NewClient(newTestCache(t), server.URL)
+
+	for range 3 {
+		pokemon, err := client.GetPokemon("pikachu")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if pokemon.Name != "pikachu" {
+			t.Errorf("expected pikachu, got %q", pokemon.Name)
+		}
+	}
+
+	if requests.Load() != 3 || notModified.Load() != 2 {
+		t.Errorf("expected 1 full and 2 conditional requests, got %d requests with %d not modified", requests.Load(), notModified.Load())
+	}
+}
+
+func TestRevalidateUpdatesValidators(t *testing.T) {
+	var mu sync.Mutex
+	var sent []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		sent = append(sent, r.Header.Get("If-None-Match"))
+		n := len(sent)
+		mu.Unlock()
+
+		w.Header().Set("Cache-Control", "max-age=0")
+		w.Header().Set("ETag", fmt.Sprintf(`"v%d"`, n))
+
+		if r.Header.Get("If-None-Match") != "" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		fmt.Fprint(w, `{"name": "pikachu"}`)
+	}))
+	defer server.Close()
+
+	client := NewClient(newTestCache(t), server.URL)
+
+	for range 3 {
+		if _, err := client.GetPokemon("pikachu"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if expected := []string{"", `"v1"`, `"v2"`}; !slices.Equal(sent, expected) {
+		t.Errorf("expected the ETag from each 304 to be sent next, got %q, want %q", sent, expected)
+	}
+}
+
+func TestMaxAgeKeepsFresh(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Cache-Control", "public, max-age=86400")
+		fmt.Fprint(w, `{"name": "pikachu"}`)
+	}))
+	defer server.Close()
+
+	// The cache's own TTL would expire the entry before the second call.
+	client := NewClient(newTestCache(t, pokecache.WithTTL(time.Nanosecond)), server.URL)
+
+	for range 2 {
+		if _, err := client.GetPokemon("pikachu"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if n := requests.Load(); n != 1 {
+		t.Errorf("expected max-age to keep the response fresh, got %d requests", n)
+	}
+}
+
+// newFlakyServer answers with version n of a Pokemon on the nth request,
+// already expired, or with a 500 once failing is set.
+func newFlakyServer(t *testing.T) (*httptest.Server, *atomic.Int32, *atomic.Bool) {
+	t.Helper()
+
+	var requests atomic.Int32
+	var failing atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := requests.Add(1)
+
+		if failing.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Cache-Control", "max-age=0")
+		fmt.Fprintf(w, `{"name": "pikachu", "order": %d}`, n)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &requests, &failing
+}
+
+func TestStaleWhileRevalidate(t *testing.T) {
+	server, requests, _ := newFlakyServer(t)
+	cache := newTestCache(t, pokecache.WithStaleWhileRevalidate(time.Minute))
+	client := NewClient(cache, server.URL)
+
+	if _, err := client.GetPokemon("pikachu"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	pokemon, err := client.GetPokemon("pikachu")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if pokemon.Order != 1 {
+		t.Errorf("expected the stale response to be returned at once, got version %d", pokemon.Order)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		e, _ := cache.Lookup(server.URL + "/pokemon/pikachu")
+		if strings.Contains(string(e.Val), `"order": 2`) {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the background refresh to update the cache, got %d requests", requests.Load())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestServeStaleOnError(t *testing.T) {
+	server, _, failing := newFlakyServer(t)
+	cache := newTestCache(t, pokecache.WithMaxStale(time.Hour))
+	client := NewClient(cache, server.URL)
+
+	var staleURL string
+	var staleErr error
+	client.OnStale = func(url string, staleFor time.Duration, err error) {
+		staleURL, staleErr = url, err
+	}
+
+	if _, err := client.GetPokemon("pikachu"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	failing.Store(true)
+
+	pokemon, err := client.GetPokemon("pikachu")
+	if err != nil {
+		t.Fatalf("expected the stale response instead of an error, got %v", err)
+	}
+
+	if pokemon.Order != 1 {
+		t.Errorf("expected the stale response, got version %d", pokemon.Order)
+	}
+
+	if staleURL != server.URL+"/pokemon/pikachu" || staleErr == nil {
+		t.Errorf("expected OnStale to be called with the URL and error, got %q, %v", staleURL, staleErr)
+	}
+
+	// Past the max staleness the error comes through.
+	cache.AddEntry(server.URL+"/pokemon/pikachu", pokecache.Entry{
+		Val:       []byte(`{"name": "pikachu"}`),
+		ExpiresAt: time.Now().Add(-2 * time.Hour),
+	})
+
+	if _, err := client.GetPokemon("pikachu"); err == nil {
+		t.Errorf("expected an error for a response past the max staleness")
+	}
+}
+
+func TestStaleWhileRevalidateReportsFailedRefresh(t *testing.T) {
+	server, _, failing := newFlakyServer(t)
+	cache := newTestCache(t, pokecache.WithStaleWhileRevalidate(time.Minute))
+	client := NewClient(cache, server.URL)
+
+	var staleErr error
+	client.OnStale = func(url string, staleFor time.Duration, err error) {
+		staleErr = err
+	}
+
+	if _, err := client.GetPokemon("pikachu"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	failing.Store(true)
+
+	// The first stale read starts a refresh that fails in the background.
+	if _, err := client.GetPokemon("pikachu"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	waitForRequests(t, client)
+
+	if staleErr != nil {
+		t.Fatalf("expected OnStale not to be called before a refresh failed, got %v", staleErr)
+	}
+
+	if _, err := client.GetPokemon("pikachu"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if staleErr == nil {
+		t.Errorf("expected OnStale to report the failed refresh")
+	}
+}
+
+func TestTimeoutServesStale(t *testing.T) {
+	var hung atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hung.Load() {
+			<-r.Context().Done()
+			return
+		}
+
+		w.Header().Set("Cache-Control", "max-age=0")
+		fmt.Fprint(w, `{"name": "pikachu"}`)
+	}))
+	defer server.Close()
+
+	client := NewClient(newTestCache(t, pokecache.WithMaxStale(time.Hour)), server.URL)
+	client.Timeout = 50 * time.Millisecond
+
+	var staleErr error
+	client.OnStale = func(url string, staleFor time.Duration, err error) {
+		staleErr = err
+	}
+
+	if _, err := client.GetPokemon("pikachu"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	hung.Store(true)
+
+	pokemon, err := client.GetPokemon("pikachu")
+	if err != nil || pokemon.Name != "pikachu" {
+		t.Fatalf("expected the stale response when the API hangs, got %v", err)
+	}
+
+	if !errors.Is(staleErr, context.DeadlineExceeded) {
+		t.Errorf("expected OnStale to report the timeout, got %v", staleErr)
+	}
+}
+
+func TestPrefetchServesStaleQuietly(t *testing.T) {
+	server, _, failing := newFlakyServer(t)
+	cache := newTestCache(t, pokecache.WithMaxStale(time.Hour))
+	client := NewClient(cache, server.URL)
+
+	var calls atomic.Int32
+	client.OnStale = func(url string, staleFor time.Duration, err error) {
+		calls.Add(1)
+	}
+
+	if _, err := client.GetPokemon("pikachu"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	failing.Store(true)
+	<-client.PrefetchPokemon(context.Background(), []string{"pikachu"}, 1)
+
+	if n := calls.Load(); n != 0 {
+		t.Errorf("expected OnStale not to be called by a prefetch, got %d calls", n)
+	}
+}
+
+func TestEndpointsUseCache(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		fmt.Fprint(w, `{"name": "test"}`)
+	}))
+	defer server.Close()
+
+	client := NewClient(newTestCache(t), server.URL)
+
+	endpoints := map[string]func() error{
+		"location areas": func() error { _, err := client.GetLocationAreas(nil); return err },
+		"location area":  func() error { _, err := client.GetLocationAreaDetails("canalave-city-area"); return err },
+		"pokemon":        func() error { _, err := client.GetPokemon("pikachu"); return err },
+		"species":        func() error { _, err := client.GetPokemonSpecies("pikachu"); return err },
+		"item":           func() error { _, err := client.GetItem("potion"); return err },
+		"move":           func() error { _, err := client.GetMove("tackle"); return err },
+	}
+
+	for name, get := range endpoints {
+		before := requests.Load()
+
+		for range 2 {
+			if err := get(); err != nil {
+				t.Fatalf("%s: unexpected error: %v", name, err)
+			}
+		}
+
+		if n := requests.Load() - before; n != 1 {
+			t.Errorf("expected %s to be cached after the first request, got %d requests", name, n)
+		}
+	}
+
+	if stats := client.Cache().Stats(); stats.Entries != len(endpoints) {
+		t.Errorf("expected %d cache entries, got %d", len(endpoints), stats.Entries)
+	}
+}
@@ -1,16 +1,12 @@
 package pokeapi
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
-	"net/http"
-
-	"github.com/ArturM94/pokedexcli/internal/pokecache"
+	"strconv"
+	"strings"
 )
 
-const baseURL = "https://pokeapi.co/api/v2"
-
 type GetLocationAreasResponse struct {
 	Count    int     `json:"count"`
 	Next     *string `json:"next"`
@@ -41,14 +37,8 @@ type GetLocationAreaDetailsResponse struct {
 		Name string `json:"name"`
 		URL  string `json:"url"`
 	} `json:"location"`
-	Name  string `json:"name"`
-	Names []struct {
-		Language struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"language"`
-		Name string `json:"name"`
-	} `json:"names"`
+	Name              string `json:"name"`
+	Names             []Name `json:"names"`
 	PokemonEncounters []struct {
 		Pokemon struct {
 			Name string `json:"name"`
@@ -74,6 +64,24 @@ type GetLocationAreaDetailsResponse struct {
 	} `json:"pokemon_encounters"`
 }
 
+type PokemonMove struct {
+	Move struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"move"`
+	VersionGroupDetails []struct {
+		LevelLearnedAt int `json:"level_learned_at"`
+		VersionGroup   struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"version_group"`
+		MoveLearnMethod struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"move_learn_method"`
+	} `json:"version_group_details"`
+}
+
 type GetPokemonResponse struct {
 	ID             int    `json:"id"`
 	Name           string `json:"name"`
@@ -114,25 +122,9 @@ type GetPokemonResponse struct {
 			} `json:"version"`
 		} `json:"version_details"`
 	} `json:"held_items"`
-	LocationAreaEncounters string `json:"location_area_encounters"`
-	Moves                  []struct {
-		Move struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"move"`
-		VersionGroupDetails []struct {
-			LevelLearnedAt int `json:"level_learned_at"`
-			VersionGroup   struct {
-				Name string `json:"name"`
-				URL  string `json:"url"`
-			} `json:"version_group"`
-			MoveLearnMethod struct {
-				Name string `json:"name"`
-				URL  string `json:"url"`
-			} `json:"move_learn_method"`
-		} `json:"version_group_details"`
-	} `json:"moves"`
-	Species struct {
+	LocationAreaEncounters string        `json:"location_area_encounters"`
+	Moves                  []PokemonMove `json:"moves"`
+	Species                struct {
 		Name string `json:"name"`
 		URL  string `json:"url"`
 	} `json:"species"`
@@ -346,109 +338,251 @@ type GetPokemonResponse struct {
 	} `json:"past_types"`
 }
 
-func GetLocationAreas(cache *pokecache.Cache, url *string) (*GetLocationAreasResponse, error) {
-	var fullURL string
-
+func (c *Client) GetLocationAreas(url *string) (*GetLocationAreasResponse, error) {
+	fullURL := c.baseURL + "/location-area"
 	if url != nil {
 		fullURL = *url
-	} else {
-		fullURL = baseURL + "/location-area"
 	}
 
-	var data []byte
+	var response GetLocationAreasResponse
+	if err := c.get(context.Background(), fullURL, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
 
-	cachedData, ok := cache.Get(fullURL)
-	if ok {
-		data = cachedData
-	} else {
-		res, err := http.Get(fullURL)
-		if err != nil {
-			return nil, fmt.Errorf("error creating request: %w", err)
-		}
-		defer res.Body.Close()
+// GetLocationAreaPage lists limit location areas starting at offset.
+func (c *Client) GetLocationAreaPage(offset, limit int) (*GetLocationAreasResponse, error) {
+	url := fmt.Sprintf("%s/location-area?offset=%d&limit=%d", c.baseURL, offset, limit)
 
-		if res.StatusCode > 299 {
-			return nil, fmt.Errorf("error getting request: status code is %d", res.StatusCode)
-		}
+	return c.GetLocationAreas(&url)
+}
 
-		data, err = io.ReadAll(res.Body)
-		if err != nil {
-			return nil, fmt.Errorf("error reading response: %w", err)
-		}
+func (c *Client) GetLocationAreaDetails(idOrName string) (*GetLocationAreaDetailsResponse, error) {
+	fullURL := c.baseURL + "/location-area/" + idOrName
 
-		cache.Add(fullURL, data)
+	var response GetLocationAreaDetailsResponse
+	if err := c.get(context.Background(), fullURL, &response); err != nil {
+		return nil, err
 	}
 
-	var response GetLocationAreasResponse
-	if err := json.Unmarshal(data, &response); err != nil {
-		return nil, fmt.Errorf("error parsing response: %w", err)
+	return &response, nil
+}
+
+func (c *Client) GetPokemon(idOrName string) (*GetPokemonResponse, error) {
+	fullURL := c.baseURL + "/pokemon/" + idOrName
+
+	var response GetPokemonResponse
+	if err := c.get(context.Background(), fullURL, &response); err != nil {
+		return nil, err
 	}
 
 	return &response, nil
 }
 
-func GetLocationAreaDetails(cache *pokecache.Cache, idOrName string) (*GetLocationAreaDetailsResponse, error) {
-	fullURL := baseURL + "/location-area/" + idOrName
-
-	var data []byte
-
-	cachedData, ok := cache.Get(fullURL)
-	if ok {
-		data = cachedData
-	} else {
-		res, err := http.Get(fullURL)
-		if err != nil {
-			return nil, fmt.Errorf("error creating request: %w", err)
-		}
-		defer res.Body.Close()
-
-		if res.StatusCode > 299 {
-			return nil, fmt.Errorf("error getting request: status code is %d", res.StatusCode)
-		}
-
-		data, err = io.ReadAll(res.Body)
-		if err != nil {
-			return nil, fmt.Errorf("error reading response: %w", err)
-		}
+type GetItemResponse struct {
+	ID         int    `json:"id"`
+	Name       string `json:"name"`
+	Cost       int    `json:"cost"`
+	FlingPower int    `json:"fling_power"`
+	Attributes []struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"attributes"`
+	Category struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"category"`
+	EffectEntries []struct {
+		Effect      string `json:"effect"`
+		ShortEffect string `json:"short_effect"`
+		Language    struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"language"`
+	} `json:"effect_entries"`
+	FlavorTextEntries []struct {
+		Text         string `json:"text"`
+		VersionGroup struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"version_group"`
+		Language struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"language"`
+	} `json:"flavor_text_entries"`
+	Names []Name `json:"names"`
+}
+
+func (c *Client) GetItem(idOrName string) (*GetItemResponse, error) {
+	fullURL := c.baseURL + "/item/" + idOrName
+
+	var response GetItemResponse
+	if err := c.get(context.Background(), fullURL, &response); err != nil {
+		return nil, err
 	}
 
-	var response GetLocationAreaDetailsResponse
-	if err := json.Unmarshal(data, &response); err != nil {
-		return nil, fmt.Errorf("error parsing response: %w", err)
+	return &response, nil
+}
+
+type GetMoveResponse struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	Accuracy     *int   `json:"accuracy"`
+	EffectChance *int   `json:"effect_chance"`
+	PP           int    `json:"pp"`
+	Priority     int    `json:"priority"`
+	Power        *int   `json:"power"`
+	DamageClass  struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"damage_class"`
+	EffectEntries []struct {
+		Effect      string `json:"effect"`
+		ShortEffect string `json:"short_effect"`
+		Language    struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"language"`
+	} `json:"effect_entries"`
+	Type struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"type"`
+}
+
+func (c *Client) GetMove(idOrName string) (*GetMoveResponse, error) {
+	fullURL := c.baseURL + "/move/" + idOrName
+
+	var response GetMoveResponse
+	if err := c.get(context.Background(), fullURL, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+type NamedAPIResource struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+type NamedAPIResourceList struct {
+	Count    int                `json:"count"`
+	Next     *string            `json:"next"`
+	Previous *string            `json:"previous"`
+	Results  []NamedAPIResource `json:"results"`
+}
+
+// Name is the name of a resource in one language.
+type Name struct {
+	Name     string           `json:"name"`
+	Language NamedAPIResource `json:"language"`
+}
+
+type GetNatureResponse struct {
+	ID            int              `json:"id"`
+	Name          string           `json:"name"`
+	IncreasedStat NamedAPIResource `json:"increased_stat"`
+	DecreasedStat NamedAPIResource `json:"decreased_stat"`
+	LikesFlavor   NamedAPIResource `json:"likes_flavor"`
+	HatesFlavor   NamedAPIResource `json:"hates_flavor"`
+}
+
+type GetPokemonSpeciesResponse struct {
+	ID                 int               `json:"id"`
+	Name               string            `json:"name"`
+	Order              int               `json:"order"`
+	GenderRate         int               `json:"gender_rate"`
+	CaptureRate        int               `json:"capture_rate"`
+	BaseHappiness      int               `json:"base_happiness"`
+	IsBaby             bool              `json:"is_baby"`
+	IsLegendary        bool              `json:"is_legendary"`
+	IsMythical         bool              `json:"is_mythical"`
+	GrowthRate         NamedAPIResource  `json:"growth_rate"`
+	Generation         NamedAPIResource  `json:"generation"`
+	EvolvesFromSpecies *NamedAPIResource `json:"evolves_from_species"`
+	Names              []Name            `json:"names"`
+	FlavorTextEntries  []struct {
+		FlavorText string           `json:"flavor_text"`
+		Language   NamedAPIResource `json:"language"`
+		Version    NamedAPIResource `json:"version"`
+	} `json:"flavor_text_entries"`
+}
+
+type GetGrowthRateResponse struct {
+	ID      int               `json:"id"`
+	Name    string            `json:"name"`
+	Formula string            `json:"formula"`
+	Levels  []GrowthRateLevel `json:"levels"`
+}
+
+type GrowthRateLevel struct {
+	Level      int `json:"level"`
+	Experience int `json:"experience"`
+}
+
+func (c *Client) GetGrowthRate(idOrName string) (*GetGrowthRateResponse, error) {
+	fullURL := c.baseURL + "/growth-rate/" + idOrName
+
+	var response GetGrowthRateResponse
+	if err := c.get(context.Background(), fullURL, &response); err != nil {
+		return nil, err
 	}
 
 	return &response, nil
 }
 
-func GetPokemon(cache *pokecache.Cache, idOrName string) (*GetPokemonResponse, error) {
-	fullURL := baseURL + "/pokemon/" + idOrName
-
-	var data []byte
-
-	cachedData, ok := cache.Get(fullURL)
-	if ok {
-		data = cachedData
-	} else {
-		res, err := http.Get(fullURL)
-		if err != nil {
-			return nil, fmt.Errorf("error creating request: %w", err)
-		}
-		defer res.Body.Close()
-
-		if res.StatusCode > 299 {
-			return nil, fmt.Errorf("error getting request: status code is %d", res.StatusCode)
-		}
-
-		data, err = io.ReadAll(res.Body)
-		if err != nil {
-			return nil, fmt.Errorf("error reading response: %w", err)
-		}
+// GetNatures lists every nature in one page.
+func (c *Client) GetNatures() (*NamedAPIResourceList, error) {
+	fullURL := c.baseURL + "/nature?limit=100"
+
+	var response NamedAPIResourceList
+	if err := c.get(context.Background(), fullURL, &response); err != nil {
+		return nil, err
 	}
 
-	var response GetPokemonResponse
-	if err := json.Unmarshal(data, &response); err != nil {
-		return nil, fmt.Errorf("error parsing response: %w", err)
+	return &response, nil
+}
+
+func (c *Client) GetNature(idOrName string) (*GetNatureResponse, error) {
+	fullURL := c.baseURL + "/nature/" + idOrName
+
+	var response GetNatureResponse
+	if err := c.get(context.Background(), fullURL, &response); err != nil {
+		return nil, err
 	}
 
 	return &response, nil
 }
+
+func (c *Client) GetPokemonSpecies(idOrName string) (*GetPokemonSpeciesResponse, error) {
+	fullURL := c.baseURL + "/pokemon-species/" + idOrName
+
+	var response GetPokemonSpeciesResponse
+	if err := c.get(context.Background(), fullURL, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// IDFromURL returns the numeric ID at the end of a resource URL such as
+// https://pokeapi.co/api/v2/pokemon/16/.
+func IDFromURL(url string) (int, error) {
+	parts := strings.Split(strings.TrimSuffix(url, "/"), "/")
+
+	id, err := strconv.Atoi(parts[len(parts)-1])
+	if err != nil {
+		return 0, fmt.Errorf("error parsing id from %s: %w", url, err)
+	}
+
+	return id, nil
+}
+
+// GetSprite downloads a sprite image.
+func (c *Client) GetSprite(url string) ([]byte, error) {
+	return c.fetch(context.Background(), url)
+}
@@ -0,0 +1,80 @@
+package pokecache
+
+import (
+	"sync"
+	"time"
+)
+
+// fakeClock only moves when told to. Advance delivers the ticks that fall
+// due, each one once the reap loop is ready to receive it.
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers []*fakeTicker
+}
+
+type fakeTicker struct {
+	c       chan time.Time
+	stopped chan struct{}
+	once    sync.Once
+	period  time.Duration
+	next    time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)}
+}
+
+func (f *fakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.now
+}
+
+func (f *fakeClock) NewTicker(d time.Duration) Ticker {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	t := &fakeTicker{
+		c:       make(chan time.Time),
+		stopped: make(chan struct{}),
+		period:  d,
+		next:    f.now.Add(d),
+	}
+	f.tickers = append(f.tickers, t)
+
+	return t
+}
+
+// Advance moves the clock forward by d and delivers the ticks that fell due
+// to tickers that haven't been stopped.
+func (f *fakeClock) Advance(d time.Duration) {
+	f.mu.Lock()
+	f.now = f.now.Add(d)
+	now := f.now
+	tickers := append([]*fakeTicker(nil), f.tickers...)
+	f.mu.Unlock()
+
+tickers:
+	for _, t := range tickers {
+		for !t.next.After(now) {
+			select {
+			case t.c <- t.next:
+			case <-t.stopped:
+				continue tickers
+			}
+			t.next = t.next.Add(t.period)
+		}
+	}
+}
+
+func (t *fakeTicker) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTicker) Stop() {
+	t.once.Do(func() {
+		close(t.stopped)
+	})
+}
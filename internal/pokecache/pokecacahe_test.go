@@ -1,11 +1,33 @@
 package pokecache
 
 import (
+	"context"
 	"fmt"
+	"runtime"
 	"testing"
 	"time"
+
+	"github.com/ArturM94/pokedexcli/internal/leakcheck"
 )
 
+// TestMain fails the tests when they leave goroutines running, such as the
+// reap loop of a cache that wasn't closed.
+func TestMain(m *testing.M) {
+	leakcheck.VerifyTestMain(m)
+}
+
+// newTestCache returns a cache on a fake clock that is closed when the test
+// ends.
+func newTestCache(t *testing.T, interval time.Duration, opts ...Option) (*Cache, *fakeClock) {
+	t.Helper()
+
+	clock := newFakeClock()
+	cache := NewCache(interval, append([]Option{WithClock(clock)}, opts...)...)
+	t.Cleanup(cache.Close)
+
+	return cache, clock
+}
+
 func TestAddGet(t *testing.T) {
 	const interval = 5 * time.Second
 	cases := []struct {
@@ -25,6 +47,8 @@ func TestAddGet(t *testing.T) {
 	for i, c := range cases {
 		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
 			cache := NewCache(interval)
+			defer cache.Close()
+
 			cache.Add(c.key, c.val)
 			val, ok := cache.Get(c.key)
 			if !ok {
@@ -42,7 +66,7 @@ func TestAddGet(t *testing.T) {
 func TestReapLoop(t *testing.T) {
 	const baseTime = 5 * time.Millisecond
 	const waitTime = baseTime + 5*time.Millisecond
-	cache := NewCache(baseTime)
+	cache, clock := newTestCache(t, baseTime, WithTTL(baseTime))
 	cache.Add("https://example.com", []byte("testdata"))
 
 	_, ok := cache.Get("https://example.com")
@@ -51,11 +75,216 @@ func TestReapLoop(t *testing.T) {
 		return
 	}
 
-	time.Sleep(waitTime)
+	clock.Advance(waitTime)
+
+	// Closing waits for the reap loop, so the tick has been handled.
+	cache.Close()
 
-	_, ok = cache.Get("https://example.com")
+	cache.mu.Lock()
+	_, ok = cache.caches["https://example.com"]
+	cache.mu.Unlock()
 	if ok {
-		t.Errorf("expected to not find key")
+		t.Errorf("expected the key to be reaped")
 		return
 	}
 }
+
+func TestClose(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	cache := NewCache(time.Millisecond)
+	cache.Add("key", []byte("value"))
+	cache.Close()
+	cache.Close()
+
+	if _, ok := cache.Get("key"); !ok {
+		t.Errorf("expected a closed cache to still work")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	NewCache(time.Millisecond, WithContext(ctx))
+	cancel()
+
+	if !leakcheck.Settle(before, time.Second) {
+		t.Errorf("expected the reap loops to exit, %d goroutines before and %d after", before, runtime.NumGoroutine())
+	}
+}
+
+func TestMaxEntries(t *testing.T) {
+	cache, _ := newTestCache(t, time.Minute, WithMaxEntries(2))
+	cache.Add("a", []byte("1"))
+	cache.Add("b", []byte("2"))
+
+	// Reading a makes b the least recently used.
+	if _, ok := cache.Get("a"); !ok {
+		t.Fatalf("expected to find a")
+	}
+
+	cache.Add("c", []byte("3"))
+
+	for key, expected := range map[string]bool{"a": true, "b": false, "c": true} {
+		if _, ok := cache.Get(key); ok != expected {
+			t.Errorf("Get(%q) found %v, want %v", key, ok, expected)
+		}
+	}
+}
+
+func TestMaxBytes(t *testing.T) {
+	// Each entry is a 1 byte key and a 4 byte value.
+	cache, _ := newTestCache(t, time.Minute, WithMaxBytes(12))
+	cache.Add("a", []byte("aaaa"))
+	cache.Add("b", []byte("bbbb"))
+	cache.Add("c", []byte("cccc"))
+
+	if _, ok := cache.Get("a"); ok {
+		t.Errorf("expected a to be evicted")
+	}
+
+	// Replacing an entry counts only its new size.
+	cache.Add("b", []byte("bb"))
+	cache.Add("d", []byte("d"))
+
+	for _, key := range []string{"b", "c", "d"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("expected to find %s", key)
+		}
+	}
+
+	cache.Add("e", []byte("this is too big to cache"))
+	if _, ok := cache.Get("e"); ok {
+		t.Errorf("expected a value over the limit not to be cached")
+	}
+
+	for _, key := range []string{"b", "c", "d"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("expected %s to be kept when a value over the limit is added", key)
+		}
+	}
+
+	if cache.bytes > 12 {
+		t.Errorf("expected at most 12 bytes, got %d", cache.bytes)
+	}
+}
+
+func TestAddWithTTL(t *testing.T) {
+	cache, clock := newTestCache(t, time.Hour, WithTTL(time.Minute), WithMaxStale(time.Minute))
+	cache.AddWithTTL("short", []byte("short"), 5*time.Millisecond)
+	cache.Add("default", []byte("default"))
+
+	clock.Advance(10 * time.Millisecond)
+
+	if _, ok := cache.Get("short"); ok {
+		t.Errorf("expected short to have expired")
+	}
+
+	if _, ok := cache.Get("default"); !ok {
+		t.Errorf("expected default to be fresh for the default TTL")
+	}
+
+	e, ok := cache.Lookup("short")
+	if !ok || string(e.Val) != "short" {
+		t.Errorf("expected Lookup to return the stale entry")
+	}
+}
+
+func TestDefaultTTL(t *testing.T) {
+	cache, clock := newTestCache(t, time.Minute)
+	cache.Add("key", []byte("value"))
+
+	clock.Advance(DefaultTTL - time.Second)
+	if _, ok := cache.Get("key"); !ok {
+		t.Errorf("expected the value to stay fresh for DefaultTTL, not the reap interval")
+	}
+
+	clock.Advance(time.Second)
+	if _, ok := cache.Get("key"); ok {
+		t.Errorf("expected the value to expire after DefaultTTL")
+	}
+}
+
+func TestReapKeepsRevalidatable(t *testing.T) {
+	const interval = 5 * time.Millisecond
+	cache, clock := newTestCache(t, interval, WithTTL(interval))
+	cache.Add("plain", []byte("plain"))
+	cache.AddEntry("tagged", Entry{Val: []byte("tagged"), ETag: `"abc"`, ExpiresAt: clock.Now().Add(interval)})
+
+	clock.Advance(3 * interval)
+	cache.Close()
+
+	if _, ok := cache.Lookup("plain"); ok {
+		t.Errorf("expected plain to be reaped")
+	}
+
+	e, ok := cache.Lookup("tagged")
+	if !ok || e.ETag != `"abc"` {
+		t.Errorf("expected tagged to be kept for revalidation")
+	}
+
+	if _, ok := cache.Get("tagged"); ok {
+		t.Errorf("expected tagged not to be fresh")
+	}
+}
+
+func TestMaxStale(t *testing.T) {
+	const interval = 5 * time.Millisecond
+	cache, clock := newTestCache(t, interval, WithMaxStale(time.Hour))
+	now := clock.Now()
+	cache.AddEntry("stale", Entry{Val: []byte("stale"), ExpiresAt: now.Add(-30 * time.Minute)})
+	cache.AddEntry("too-stale", Entry{Val: []byte("too-stale"), ETag: `"abc"`, ExpiresAt: now.Add(-2 * time.Hour)})
+	cache.AddEntry("reaped", Entry{Val: []byte("reaped"), ExpiresAt: now.Add(-2 * time.Hour)})
+
+	if _, ok := cache.Lookup("stale"); !ok {
+		t.Errorf("expected an entry within the max staleness to be found")
+	}
+
+	if _, ok := cache.Lookup("too-stale"); ok {
+		t.Errorf("expected an entry past the max staleness not to be found, even with a validator")
+	}
+
+	clock.Advance(3 * interval)
+	cache.Close()
+
+	cache.mu.Lock()
+	_, reaped := cache.caches["reaped"]
+	_, stale := cache.caches["stale"]
+	cache.mu.Unlock()
+
+	if reaped || !stale {
+		t.Errorf("expected only entries past the max staleness to be reaped")
+	}
+}
+
+func TestStats(t *testing.T) {
+	cache, clock := newTestCache(t, time.Second, WithTTL(time.Minute), WithMaxEntries(2))
+	cache.Add("a", []byte("aaaa"))
+	cache.Add("b", []byte("bbbb"))
+
+	cache.Get("a")
+	cache.Get("a")
+	cache.Get("missing")
+
+	// c pushes out b, the least recently used.
+	cache.Add("c", []byte("cccc"))
+
+	cache.AddWithTTL("d", []byte("dddd"), time.Millisecond)
+	clock.Advance(time.Second)
+	cache.Close()
+
+	expected := Stats{Hits: 2, Misses: 1, Evictions: 2, Expired: 1, Entries: 1, Bytes: 5}
+	if actual := cache.Stats(); actual != expected {
+		t.Errorf("Stats() == %+v, want %+v", actual, expected)
+	}
+
+	items := cache.Items()
+	if len(items) != 1 || items[0].Key != "c" || items[0].Size() != 5 {
+		t.Errorf("Items() == %+v, want only c", items)
+	}
+
+	if n := cache.Clear(); n != 1 {
+		t.Errorf("Clear() == %d, want 1", n)
+	}
+
+	if stats := cache.Stats(); stats.Entries != 0 || stats.Bytes != 0 || stats.Hits != 2 {
+		t.Errorf("expected Clear to empty the cache and keep the counts, got %+v", stats)
+	}
+}
@@ -1,64 +1,385 @@
 package pokecache
 
 import (
+	"container/list"
+	"context"
 	"sync"
 	"time"
 )
 
+// DefaultTTL is how long values added with Add stay fresh unless the cache
+// is given another TTL with WithTTL.
+const DefaultTTL = 5 * time.Minute
+
+// Entry is a cached value along with what's needed to revalidate it once it
+// expires.
+type Entry struct {
+	Val []byte
+	// ETag and LastModified are the validators the value was served with.
+	ETag         string
+	LastModified string
+	// ExpiresAt is when the value stops being fresh.
+	ExpiresAt time.Time
+}
+
+// Fresh reports whether the value can still be used without revalidating.
+func (e Entry) Fresh(now time.Time) bool {
+	return now.Before(e.ExpiresAt)
+}
+
+// Revalidatable reports whether the entry has a validator for a
+// conditional request.
+func (e Entry) Revalidatable() bool {
+	return e.ETag != "" || e.LastModified != ""
+}
+
+// Item is an entry along with its key, as listed by Items.
+type Item struct {
+	Key string
+	Entry
+}
+
+// Size is what the item counts towards the cache's byte limit.
+func (it Item) Size() int {
+	return len(it.Key) + len(it.Val) + len(it.ETag) + len(it.LastModified)
+}
+
+// Stats describe how the cache has been used.
+type Stats struct {
+	// Hits and Misses count the calls to Get that found a fresh value and
+	// those that didn't.
+	Hits   int
+	Misses int
+	// Evictions counts the entries dropped to stay within the size limits,
+	// and Expired those dropped for being too stale.
+	Evictions int
+	Expired   int
+	Entries   int
+	Bytes     int
+}
+
 type cacheEnrty struct {
-	createdAt time.Time
-	val       []byte
+	key   string
+	entry Entry
+}
+
+func (e *cacheEnrty) size() int {
+	return Item{Key: e.key, Entry: e.entry}.Size()
 }
 
 type Cache struct {
-	caches map[string]cacheEnrty
-	mu     sync.Mutex
+	caches map[string]*list.Element
+	// lru orders the entries from the most to the least recently used.
+	lru   *list.List
+	bytes int
+	stats Stats
+	mu    sync.Mutex
+
+	clock Clock
+	ctx   context.Context
+	// stop ends the reap loop, which closes done when it returns.
+	stop context.CancelFunc
+	done chan struct{}
+
+	ttl                  time.Duration
+	staleWhileRevalidate time.Duration
+	maxStale             time.Duration
+	maxEntries           int
+	maxBytes             int
+}
+
+// Option configures a Cache.
+type Option func(*Cache)
+
+// WithClock makes the cache tell the time and schedule reaping with clock
+// instead of the time package.
+func WithClock(clock Clock) Option {
+	return func(c *Cache) {
+		c.clock = clock
+	}
+}
+
+// WithContext closes the cache once ctx is done.
+func WithContext(ctx context.Context) Option {
+	return func(c *Cache) {
+		c.ctx = ctx
+	}
+}
+
+// WithTTL sets how long values added with Add stay fresh. It defaults to
+// DefaultTTL.
+func WithTTL(ttl time.Duration) Option {
+	return func(c *Cache) {
+		c.ttl = ttl
+	}
+}
+
+// WithStaleWhileRevalidate lets values that expired less than d ago be
+// used right away while they are refreshed in the background.
+func WithStaleWhileRevalidate(d time.Duration) Option {
+	return func(c *Cache) {
+		c.staleWhileRevalidate = d
+	}
+}
+
+// WithMaxStale keeps entries for up to d after they expire, so they can be
+// used when refreshing them fails. Entries are also kept for the stale while
+// revalidate window. Without either, expired entries are only kept when they
+// can be revalidated.
+func WithMaxStale(d time.Duration) Option {
+	return func(c *Cache) {
+		c.maxStale = d
+	}
 }
 
-func NewCache(interval time.Duration) *Cache {
-	caches := make(map[string]cacheEnrty)
+// WithMaxEntries limits the cache to n entries, evicting the least recently
+// used ones to make room. Zero means no limit.
+func WithMaxEntries(n int) Option {
+	return func(c *Cache) {
+		c.maxEntries = n
+	}
+}
+
+// WithMaxBytes limits the total size of the entries in the cache, evicting
+// the least recently used ones to make room. A value too big to fit on its
+// own isn't cached. Zero means no limit.
+func WithMaxBytes(n int) Option {
+	return func(c *Cache) {
+		c.maxBytes = n
+	}
+}
+
+// NewCache returns a cache that looks for expired entries every interval,
+// dropping those that are past the limits set with WithMaxStale. It runs a
+// goroutine until it is closed with Close or through WithContext.
+func NewCache(interval time.Duration, opts ...Option) *Cache {
+	caches := make(map[string]*list.Element)
 
 	cache := &Cache{
 		caches: caches,
+		lru:    list.New(),
 		mu:     sync.Mutex{},
+		ttl:    DefaultTTL,
+		clock:  realClock{},
+		ctx:    context.Background(),
+		done:   make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(cache)
 	}
 
-	cache.reapLoop(interval)
+	var ctx context.Context
+	ctx, cache.stop = context.WithCancel(cache.ctx)
+	go cache.reapLoop(ctx, cache.clock.NewTicker(interval))
 
 	return cache
 }
 
+// Close stops the cache from reaping expired entries and waits for its
+// goroutine to exit. The cache can still be used afterwards, and closing it
+// again does nothing.
+func (c *Cache) Close() {
+	c.stop()
+	<-c.done
+}
+
+// Now is the time according to the cache's clock.
+func (c *Cache) Now() time.Time {
+	return c.clock.Now()
+}
+
+// TTL is how long values added with Add stay fresh.
+func (c *Cache) TTL() time.Duration {
+	return c.ttl
+}
+
 func (c *Cache) Add(key string, val []byte) {
+	c.AddWithTTL(key, val, c.ttl)
+}
+
+// StaleWhileRevalidate is how long after expiring a value can be used while
+// it is refreshed in the background.
+func (c *Cache) StaleWhileRevalidate() time.Duration {
+	return c.staleWhileRevalidate
+}
+
+// AddWithTTL stores val for key, fresh for ttl.
+func (c *Cache) AddWithTTL(key string, val []byte, ttl time.Duration) {
+	c.AddEntry(key, Entry{Val: val, ExpiresAt: c.clock.Now().Add(ttl)})
+}
+
+// AddEntry stores e for key, replacing any previous entry.
+func (c *Cache) AddEntry(key string, e Entry) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.caches[key] = cacheEnrty{createdAt: time.Now(), val: val}
+	if elem, ok := c.caches[key]; ok {
+		c.remove(elem)
+	}
+
+	// A value too big for the cache on its own isn't stored, rather than
+	// evicting everything else before being evicted itself.
+	entry := &cacheEnrty{key: key, entry: e}
+	if c.maxBytes > 0 && entry.size() > c.maxBytes {
+		return
+	}
+
+	c.caches[key] = c.lru.PushFront(entry)
+	c.bytes += entry.size()
+
+	c.evict()
 }
 
+// Get returns the value stored for key while it is fresh, marking it as
+// recently used.
 func (c *Cache) Get(key string) ([]byte, bool) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if value, ok := c.caches[key]; ok {
-		return value.val, true
+	e, ok := c.lookup(key)
+	if !ok || !e.Fresh(c.clock.Now()) {
+		c.stats.Misses++
+		return nil, false
 	}
 
-	return nil, false
+	c.stats.Hits++
+
+	return e.Val, true
+}
+
+// Lookup returns the entry for key even when it has expired, as long as it
+// is within the staleness limits, so it can be revalidated or used when
+// refreshing it fails. It marks the entry as recently used.
+func (c *Cache) Lookup(key string) (Entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.lookup(key)
 }
 
-func (c *Cache) reapLoop(interval time.Duration) {
-	ticker := time.NewTicker(interval)
-	go func() {
-		for range ticker.C {
-			c.mu.Lock()
+func (c *Cache) lookup(key string) (Entry, bool) {
+	elem, ok := c.caches[key]
+	if !ok {
+		return Entry{}, false
+	}
 
-			for key, value := range c.caches {
-				if time.Since(value.createdAt) > interval {
-					delete(c.caches, key)
-				}
-			}
+	e := elem.Value.(*cacheEnrty).entry
+	if c.tooStale(e, c.clock.Now()) {
+		c.remove(elem)
+		c.stats.Expired++
+		return Entry{}, false
+	}
+
+	c.lru.MoveToFront(elem)
+
+	return e, true
+}
+
+// tooStale reports whether e has expired and is no longer worth keeping.
+func (c *Cache) tooStale(e Entry, now time.Time) bool {
+	if e.Fresh(now) {
+		return false
+	}
+
+	if limit := max(c.maxStale, c.staleWhileRevalidate); limit > 0 {
+		return now.Sub(e.ExpiresAt) > limit
+	}
 
-			c.mu.Unlock()
+	return !e.Revalidatable()
+}
+
+// evict drops the least recently used entries until the cache is within its
+// limits.
+func (c *Cache) evict() {
+	for c.lru.Len() > 0 &&
+		((c.maxEntries > 0 && c.lru.Len() > c.maxEntries) || (c.maxBytes > 0 && c.bytes > c.maxBytes)) {
+		c.remove(c.lru.Back())
+		c.stats.Evictions++
+	}
+}
+
+// Peek returns the entry for key without marking it as used or counting
+// towards the stats, however stale it is.
+func (c *Cache) Peek(key string) (Entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.caches[key]; ok {
+		return elem.Value.(*cacheEnrty).entry, true
+	}
+
+	return Entry{}, false
+}
+
+// Items lists the entries from the most to the least recently used.
+func (c *Cache) Items() []Item {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	items := make([]Item, 0, c.lru.Len())
+	for elem := c.lru.Front(); elem != nil; elem = elem.Next() {
+		e := elem.Value.(*cacheEnrty)
+		items = append(items, Item{Key: e.key, Entry: e.entry})
+	}
+
+	return items
+}
+
+// Clear removes every entry and returns how many there were. The counts of
+// hits, misses, evictions and expired entries are kept.
+func (c *Cache) Clear() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := c.lru.Len()
+	c.caches = make(map[string]*list.Element)
+	c.lru.Init()
+	c.bytes = 0
+
+	return n
+}
+
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Entries = c.lru.Len()
+	stats.Bytes = c.bytes
+
+	return stats
+}
+
+func (c *Cache) remove(elem *list.Element) {
+	entry := c.lru.Remove(elem).(*cacheEnrty)
+	delete(c.caches, entry.key)
+	c.bytes -= entry.size()
+}
+
+func (c *Cache) reapLoop(ctx context.Context, ticker Ticker) {
+	defer close(c.done)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C():
+			c.reap()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (c *Cache) reap() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.clock.Now()
+	for _, elem := range c.caches {
+		if c.tooStale(elem.Value.(*cacheEnrty).entry, now) {
+			c.remove(elem)
+			c.stats.Expired++
 		}
-	}()
+	}
 }
@@ -0,0 +1,231 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"strings"
+)
+
+type Mode int
+
+const (
+	// TrueColor draws two pixels per cell with 24-bit ANSI colors.
+	TrueColor Mode = iota
+	// Color256 draws two pixels per cell with the xterm 256-color palette.
+	Color256
+	// ASCII draws shaded characters for terminals without color.
+	ASCII
+)
+
+// asciiRamp goes from the darkest to the lightest shade.
+const asciiRamp = "@%#*+=-:. "
+
+const reset = "\x1b[0m"
+
+type Options struct {
+	Mode Mode
+	// MaxWidth scales the image down to at most this many columns. Zero
+	// keeps the original size.
+	MaxWidth int
+}
+
+// ParseMode reads a mode name as given on the command line.
+func ParseMode(name string) (Mode, error) {
+	switch name {
+	case "truecolor", "24bit":
+		return TrueColor, nil
+	case "256", "256color":
+		return Color256, nil
+	case "ascii":
+		return ASCII, nil
+	default:
+		return 0, fmt.Errorf("unknown render mode: %s", name)
+	}
+}
+
+// DetectMode picks the richest mode the terminal advertises through its
+// environment.
+func DetectMode(getenv func(string) string) Mode {
+	colorTerm := getenv("COLORTERM")
+	if colorTerm == "truecolor" || colorTerm == "24bit" {
+		return TrueColor
+	}
+
+	if strings.Contains(getenv("TERM"), "256color") {
+		return Color256
+	}
+
+	return ASCII
+}
+
+func Decode(data []byte) (image.Image, error) {
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("error decoding sprite: %w", err)
+	}
+
+	return img, nil
+}
+
+// Render draws img for the terminal. Transparent borders are cropped away
+// first, since sprites usually sit in the middle of a larger canvas.
+func Render(img image.Image, opts Options) string {
+	img = scale(crop(img), opts.MaxWidth)
+	bounds := img.Bounds()
+
+	var sb strings.Builder
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += 2 {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			top := pixelAt(img, x, y)
+			bottom := pixelAt(img, x, y+1)
+
+			if opts.Mode == ASCII {
+				sb.WriteByte(asciiCell(top, bottom))
+				continue
+			}
+
+			sb.WriteString(colorCell(top, bottom, opts.Mode))
+		}
+
+		if opts.Mode != ASCII {
+			sb.WriteString(reset)
+		}
+		sb.WriteByte('\n')
+	}
+
+	return sb.String()
+}
+
+type pixel struct {
+	r, g, b uint8
+	opaque  bool
+}
+
+func pixelAt(img image.Image, x, y int) pixel {
+	if !(image.Point{X: x, Y: y}).In(img.Bounds()) {
+		return pixel{}
+	}
+
+	r, g, b, a := img.At(x, y).RGBA()
+	if a < 0x8000 {
+		return pixel{}
+	}
+
+	// Undo the alpha premultiplication of partly transparent pixels.
+	return pixel{
+		r:      uint8(r * 0xffff / a >> 8),
+		g:      uint8(g * 0xffff / a >> 8),
+		b:      uint8(b * 0xffff / a >> 8),
+		opaque: true,
+	}
+}
+
+func colorCell(top, bottom pixel, mode Mode) string {
+	switch {
+	case !top.opaque && !bottom.opaque:
+		return reset + " "
+	case !bottom.opaque:
+		return reset + foreground(top, mode) + "▀"
+	case !top.opaque:
+		return reset + foreground(bottom, mode) + "▄"
+	default:
+		return foreground(top, mode) + background(bottom, mode) + "▀"
+	}
+}
+
+func foreground(p pixel, mode Mode) string {
+	if mode == Color256 {
+		return fmt.Sprintf("\x1b[38;5;%dm", to256(p))
+	}
+
+	return fmt.Sprintf("\x1b[38;2;%d;%d;%dm", p.r, p.g, p.b)
+}
+
+func background(p pixel, mode Mode) string {
+	if mode == Color256 {
+		return fmt.Sprintf("\x1b[48;5;%dm", to256(p))
+	}
+
+	return fmt.Sprintf("\x1b[48;2;%d;%d;%dm", p.r, p.g, p.b)
+}
+
+// to256 maps a color onto the 6x6x6 color cube of the xterm palette.
+func to256(p pixel) int {
+	level := func(c uint8) int {
+		return (int(c)*5 + 127) / 255
+	}
+
+	return 16 + 36*level(p.r) + 6*level(p.g) + level(p.b)
+}
+
+func asciiCell(top, bottom pixel) byte {
+	var sum, count int
+	for _, p := range []pixel{top, bottom} {
+		if p.opaque {
+			sum += (299*int(p.r) + 587*int(p.g) + 114*int(p.b)) / 1000
+			count++
+		}
+	}
+
+	if count == 0 {
+		return ' '
+	}
+
+	// The lightest shade is a space, which is kept for transparency.
+	shades := len(asciiRamp) - 1
+	return asciiRamp[min(sum/count*shades/256, shades-1)]
+}
+
+// crop trims fully transparent rows and columns from the edges.
+func crop(img image.Image) image.Image {
+	bounds := img.Bounds()
+	box := image.Rectangle{Min: bounds.Max, Max: bounds.Min}
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if !pixelAt(img, x, y).opaque {
+				continue
+			}
+
+			box.Min.X = min(box.Min.X, x)
+			box.Min.Y = min(box.Min.Y, y)
+			box.Max.X = max(box.Max.X, x+1)
+			box.Max.Y = max(box.Max.Y, y+1)
+		}
+	}
+
+	if box.Empty() {
+		return img
+	}
+
+	cropped := image.NewRGBA(image.Rect(0, 0, box.Dx(), box.Dy()))
+	for y := 0; y < box.Dy(); y++ {
+		for x := 0; x < box.Dx(); x++ {
+			cropped.Set(x, y, img.At(box.Min.X+x, box.Min.Y+y))
+		}
+	}
+
+	return cropped
+}
+
+// scale shrinks img with nearest neighbor sampling to fit maxWidth.
+func scale(img image.Image, maxWidth int) image.Image {
+	bounds := img.Bounds()
+	if maxWidth <= 0 || bounds.Dx() <= maxWidth {
+		return img
+	}
+
+	width := maxWidth
+	height := max(1, bounds.Dy()*maxWidth/bounds.Dx())
+
+	scaled := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			scaled.Set(x, y, img.At(bounds.Min.X+x*bounds.Dx()/width, bounds.Min.Y+y*bounds.Dy()/height))
+		}
+	}
+
+	return scaled
+}
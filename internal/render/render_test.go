@@ -0,0 +1,94 @@
+package render
+
+import (
+	"image"
+	"os"
+	"testing"
+)
+
+func loadFixture(t *testing.T, name string) image.Image {
+	t.Helper()
+
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatalf("error reading fixture: %v", err)
+	}
+
+	img, err := Decode(data)
+	if err != nil {
+		t.Fatalf("error decoding fixture: %v", err)
+	}
+
+	return img
+}
+
+func TestRender(t *testing.T) {
+	cases := []struct {
+		fixture  string
+		opts     Options
+		expected string
+	}{
+		{
+			fixture: "quad.png",
+			opts:    Options{Mode: TrueColor},
+			expected: "\x1b[38;2;255;0;0m\x1b[48;2;0;0;255m▀" +
+				"\x1b[0m\x1b[38;2;255;255;255m▀" +
+				"\x1b[0m\n",
+		},
+		{
+			fixture: "quad.png",
+			opts:    Options{Mode: Color256},
+			expected: "\x1b[38;5;196m\x1b[48;5;21m▀" +
+				"\x1b[0m\x1b[38;5;231m▀" +
+				"\x1b[0m\n",
+		},
+		{
+			fixture:  "quad.png",
+			opts:     Options{Mode: ASCII},
+			expected: "%.\n",
+		},
+		{
+			fixture:  "padded.png",
+			opts:     Options{Mode: ASCII},
+			expected: "@.\n",
+		},
+		{
+			fixture:  "padded.png",
+			opts:     Options{Mode: ASCII, MaxWidth: 1},
+			expected: "@\n",
+		},
+	}
+
+	for _, c := range cases {
+		actual := Render(loadFixture(t, c.fixture), c.opts)
+		if actual != c.expected {
+			t.Errorf("Render(%s, %+v) == %q, want %q", c.fixture, c.opts, actual, c.expected)
+		}
+	}
+}
+
+func TestDetectMode(t *testing.T) {
+	cases := []struct {
+		env      map[string]string
+		expected Mode
+	}{
+		{env: map[string]string{"COLORTERM": "truecolor", "TERM": "xterm-256color"}, expected: TrueColor},
+		{env: map[string]string{"TERM": "xterm-256color"}, expected: Color256},
+		{env: map[string]string{"TERM": "dumb"}, expected: ASCII},
+	}
+
+	for _, c := range cases {
+		actual := DetectMode(func(key string) string {
+			return c.env[key]
+		})
+		if actual != c.expected {
+			t.Errorf("DetectMode(%v) == %v, want %v", c.env, actual, c.expected)
+		}
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	if _, err := Decode([]byte("not a png")); err == nil {
+		t.Errorf("expected an error decoding garbage")
+	}
+}
@@ -0,0 +1,244 @@
+package search
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ArturM94/pokedexcli/internal/atomicfile"
+	"github.com/ArturM94/pokedexcli/internal/fuzzy"
+)
+
+// How well a result matches the query, best first.
+const (
+	Exact = iota
+	Prefix
+	Substring
+	Fuzzy
+)
+
+// Index holds the names of every resource of each kind (pokemon, move,
+// ...), as used by the API, along with their localized names where known.
+type Index struct {
+	Kinds map[string][]Entry `json:"kinds"`
+}
+
+type Entry struct {
+	Name string `json:"name"`
+	// Localized maps a language such as ja to the name in that language.
+	Localized map[string]string `json:"localized,omitempty"`
+}
+
+type Result struct {
+	Kind string
+	Name string
+	// Matched is the text that matched the query: Name or a localized name.
+	Matched  string
+	Language string
+	Rank     int
+	Distance int
+}
+
+func New() *Index {
+	return &Index{Kinds: map[string][]Entry{}}
+}
+
+// Load reads an index saved with Save. A missing file gives an empty index.
+func Load(path string) (*Index, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return New(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading search index: %w", err)
+	}
+
+	idx := New()
+	if err := json.Unmarshal(data, idx); err != nil {
+		return nil, fmt.Errorf("error parsing search index: %w", err)
+	}
+
+	if idx.Kinds == nil {
+		idx.Kinds = map[string][]Entry{}
+	}
+
+	return idx, nil
+}
+
+// Save writes the index to path, replacing any previous file atomically.
+func (idx *Index) Save(path string) error {
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return fmt.Errorf("error encoding search index: %w", err)
+	}
+
+	if err := atomicfile.WriteFile(path, data); err != nil {
+		return fmt.Errorf("error writing search index: %w", err)
+	}
+
+	return nil
+}
+
+func (idx *Index) Has(kind string) bool {
+	_, ok := idx.Kinds[kind]
+	return ok
+}
+
+// Names returns the names of kind in sorted order.
+func (idx *Index) Names(kind string) []string {
+	entries := idx.Kinds[kind]
+
+	names := make([]string, len(entries))
+	for i, e := range entries {
+		names[i] = e.Name
+	}
+
+	return names
+}
+
+// Contains reports whether kind has a resource called name.
+func (idx *Index) Contains(kind, name string) bool {
+	_, ok := idx.find(kind, name)
+	return ok
+}
+
+// Set replaces the names of kind, keeping the localized names already known
+// for the ones that remain.
+func (idx *Index) Set(kind string, names []string) {
+	entries := make([]Entry, 0, len(names))
+	for _, name := range names {
+		e := Entry{Name: name}
+		if i, ok := idx.find(kind, name); ok {
+			e.Localized = idx.Kinds[kind][i].Localized
+		}
+
+		entries = append(entries, e)
+	}
+
+	slices.SortFunc(entries, func(a, b Entry) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	entries = slices.CompactFunc(entries, func(a, b Entry) bool {
+		return a.Name == b.Name
+	})
+
+	idx.Kinds[kind] = entries
+}
+
+// AddLocalized records the name of a resource in language. It reports
+// whether the index changed. Names of resources not in the index are
+// ignored.
+func (idx *Index) AddLocalized(kind, name, language, localized string) bool {
+	i, ok := idx.find(kind, name)
+	if !ok {
+		return false
+	}
+
+	e := &idx.Kinds[kind][i]
+	if e.Localized[language] == localized {
+		return false
+	}
+
+	if e.Localized == nil {
+		e.Localized = map[string]string{}
+	}
+	e.Localized[language] = localized
+
+	return true
+}
+
+func (idx *Index) find(kind, name string) (int, bool) {
+	return slices.BinarySearchFunc(idx.Kinds[kind], name, func(e Entry, name string) int {
+		return cmp.Compare(e.Name, name)
+	})
+}
+
+// Search looks up query in the names of kinds, or of every kind when kinds
+// is empty. Results are ranked exact matches first, then names starting
+// with the query, names containing it and finally names within a few typos
+// of it. Case is ignored and spaces match hyphens.
+func (idx *Index) Search(query string, kinds []string, limit int) []Result {
+	query = fold(query)
+	if query == "" {
+		return nil
+	}
+
+	if len(kinds) == 0 {
+		for kind := range idx.Kinds {
+			kinds = append(kinds, kind)
+		}
+	}
+
+	var results []Result
+	for _, kind := range kinds {
+		for _, e := range idx.Kinds[kind] {
+			best, ok := match(query, e.Name)
+			best.Kind, best.Name, best.Matched = kind, e.Name, e.Name
+
+			for language, localized := range e.Localized {
+				r, found := match(query, localized)
+				if found && (!ok || better(r, best)) {
+					best, ok = r, true
+					best.Kind, best.Name, best.Matched, best.Language = kind, e.Name, localized, language
+				}
+			}
+
+			if ok {
+				results = append(results, best)
+			}
+		}
+	}
+
+	slices.SortFunc(results, func(a, b Result) int {
+		if better(a, b) {
+			return -1
+		}
+		if better(b, a) {
+			return 1
+		}
+		return cmp.Or(cmp.Compare(a.Kind, b.Kind), cmp.Compare(a.Name, b.Name))
+	})
+
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results
+}
+
+func match(query, name string) (Result, bool) {
+	folded := fold(name)
+
+	switch {
+	case folded == query:
+		return Result{Rank: Exact}, true
+	case strings.HasPrefix(folded, query):
+		return Result{Rank: Prefix, Distance: utf8.RuneCountInString(folded) - utf8.RuneCountInString(query)}, true
+	case strings.Contains(folded, query):
+		return Result{Rank: Substring, Distance: utf8.RuneCountInString(folded) - utf8.RuneCountInString(query)}, true
+	}
+
+	if d := fuzzy.Distance(query, folded); d <= fuzzy.MaxDistance(query) {
+		return Result{Rank: Fuzzy, Distance: d}, true
+	}
+
+	return Result{}, false
+}
+
+// better reports whether a ranks above b. Within a rank, closer matches
+// come first: fewer typos or fewer characters beyond the query.
+func better(a, b Result) bool {
+	if a.Rank != b.Rank {
+		return a.Rank < b.Rank
+	}
+	return a.Distance < b.Distance
+}
+
+func fold(s string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(s)), " ", "-")
+}
@@ -0,0 +1,106 @@
+package search
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func newIndex() *Index {
+	idx := New()
+	idx.Set("pokemon", []string{"pikachu", "raichu", "mr-mime", "pichu", "charmander"})
+	idx.Set("move", []string{"thunder", "thunderbolt", "thunder-punch", "tackle"})
+	idx.Set("item", []string{"poke-ball", "potion"})
+	idx.AddLocalized("pokemon", "pikachu", "ja-hrkt", "ピカチュウ")
+	idx.AddLocalized("pokemon", "mr-mime", "fr", "M. Mime")
+
+	return idx
+}
+
+func TestSearch(t *testing.T) {
+	idx := newIndex()
+
+	cases := []struct {
+		query    string
+		kinds    []string
+		expected []string
+	}{
+		{query: "thunder", expected: []string{"thunder", "thunderbolt", "thunder-punch"}},
+		{query: "Thunder Punch", expected: []string{"thunder-punch"}},
+		{query: "chu", expected: []string{"pichu", "raichu", "pikachu"}},
+		{query: "pikachoo", expected: []string{"pikachu"}},
+		{query: "ピカ", expected: []string{"pikachu"}},
+		{query: "m. mime", expected: []string{"mr-mime"}},
+		{query: "p", kinds: []string{"item"}, expected: []string{"potion", "poke-ball"}},
+		{query: "mewtwo", expected: nil},
+		{query: " ", expected: nil},
+	}
+
+	for _, c := range cases {
+		var names []string
+		for _, r := range idx.Search(c.query, c.kinds, 10) {
+			names = append(names, r.Name)
+		}
+
+		if !slices.Equal(names, c.expected) {
+			t.Errorf("Search(%q, %v) == %q, want %q", c.query, c.kinds, names, c.expected)
+		}
+	}
+}
+
+func TestSearchMatchedLocalized(t *testing.T) {
+	results := newIndex().Search("ピカチュウ", nil, 10)
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+
+	if r := results[0]; r.Matched != "ピカチュウ" || r.Language != "ja-hrkt" || r.Rank != Exact {
+		t.Errorf("expected an exact match on the ja-hrkt name, got %+v", r)
+	}
+}
+
+func TestSetKeepsLocalized(t *testing.T) {
+	idx := newIndex()
+	idx.Set("pokemon", []string{"pikachu", "eevee"})
+
+	if !idx.Contains("pokemon", "eevee") || idx.Contains("pokemon", "raichu") {
+		t.Errorf("expected the pokemon names to be replaced, got %v", idx.Names("pokemon"))
+	}
+
+	if name := idx.Kinds["pokemon"][1].Localized["ja-hrkt"]; name != "ピカチュウ" {
+		t.Errorf("expected the localized name to be kept, got %q", name)
+	}
+
+	if idx.AddLocalized("pokemon", "mewtwo", "fr", "Mewtwo") {
+		t.Errorf("expected names of unknown resources to be ignored")
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache", "index.json")
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(loaded.Kinds) != 0 {
+		t.Errorf("expected a missing file to give an empty index")
+	}
+
+	if err := newIndex().Save(path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	loaded, err = Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !slices.Equal(loaded.Names("move"), newIndex().Names("move")) {
+		t.Errorf("Names(move) == %v after loading, want %v", loaded.Names("move"), newIndex().Names("move"))
+	}
+
+	if results := loaded.Search("ピカチュウ", nil, 1); len(results) != 1 {
+		t.Errorf("expected localized names to be saved")
+	}
+}
@@ -0,0 +1,32 @@
+package trainer
+
+const MaxLevel = 100
+
+// GrowthRate holds the total experience needed to reach each level, with
+// index 0 for level 1.
+type GrowthRate []int
+
+// ExperienceFor returns the total experience needed to reach level.
+func (g GrowthRate) ExperienceFor(level int) int {
+	if level < 1 || len(g) == 0 {
+		return 0
+	}
+
+	return g[min(level, len(g))-1]
+}
+
+// GainExperience adds exp and raises the level as far as the growth rate
+// allows. It returns every level reached on the way, in order.
+func (p *Pokemon) GainExperience(exp int, growth GrowthRate) []int {
+	// Pokemon from old saves start with no experience at all.
+	p.Experience = max(p.Experience, growth.ExperienceFor(p.Level))
+	p.Experience += exp
+
+	var reached []int
+	for p.Level < MaxLevel && p.Level < len(growth) && p.Experience >= growth.ExperienceFor(p.Level+1) {
+		p.Level++
+		reached = append(reached, p.Level)
+	}
+
+	return reached
+}
@@ -0,0 +1,47 @@
+package trainer
+
+import (
+	"slices"
+	"testing"
+)
+
+// mediumFast is the medium-fast growth rate, where level n needs n^3 exp.
+func mediumFast() GrowthRate {
+	growth := make(GrowthRate, MaxLevel)
+	for i := range growth {
+		level := i + 1
+		growth[i] = level * level * level
+	}
+	growth[0] = 0
+
+	return growth
+}
+
+func TestGainExperience(t *testing.T) {
+	cases := []struct {
+		level         int
+		experience    int
+		gain          int
+		expected      []int
+		expectedLevel int
+	}{
+		{level: 5, experience: 125, gain: 50, expected: nil, expectedLevel: 5},
+		{level: 5, experience: 125, gain: 91, expected: []int{6}, expectedLevel: 6},
+		{level: 5, experience: 125, gain: 1000, expected: []int{6, 7, 8, 9, 10}, expectedLevel: 10},
+		{level: 5, experience: 0, gain: 91, expected: []int{6}, expectedLevel: 6},
+		{level: 100, experience: 1000000, gain: 5000, expected: nil, expectedLevel: 100},
+	}
+
+	for _, c := range cases {
+		p := &Pokemon{Level: c.level, Experience: c.experience}
+		reached := p.GainExperience(c.gain, mediumFast())
+
+		if !slices.Equal(reached, c.expected) {
+			t.Errorf("GainExperience(%d) at level %d reached %v, want %v", c.gain, c.level, reached, c.expected)
+		}
+
+		if p.Level != c.expectedLevel {
+			t.Errorf("expected level %d, got %d", c.expectedLevel, p.Level)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package trainer
+
+// NationalDexSize is the number of species in the National Pokedex.
+const NationalDexSize = 1025
+
+type Generation struct {
+	Name   string
+	Region string
+	First  int
+	Last   int
+}
+
+// Generations splits the National Pokedex by the generation that introduced
+// each species.
+var Generations = []Generation{
+	{Name: "Generation I", Region: "Kanto", First: 1, Last: 151},
+	{Name: "Generation II", Region: "Johto", First: 152, Last: 251},
+	{Name: "Generation III", Region: "Hoenn", First: 252, Last: 386},
+	{Name: "Generation IV", Region: "Sinnoh", First: 387, Last: 493},
+	{Name: "Generation V", Region: "Unova", First: 494, Last: 649},
+	{Name: "Generation VI", Region: "Kalos", First: 650, Last: 721},
+	{Name: "Generation VII", Region: "Alola", First: 722, Last: 809},
+	{Name: "Generation VIII", Region: "Galar", First: 810, Last: 905},
+	{Name: "Generation IX", Region: "Paldea", First: 906, Last: 1025},
+}
+
+// DexEntry records a species the trainer has come across.
+type DexEntry struct {
+	Name   string `json:"name"`
+	Caught bool   `json:"caught"`
+}
+
+// See records a species as seen, keeping it caught if it already was.
+func (t *Trainer) See(dexID int, name string) {
+	if _, ok := t.Pokedex[dexID]; ok {
+		return
+	}
+
+	t.Pokedex[dexID] = DexEntry{Name: name}
+}
+
+func (t *Trainer) MarkCaught(dexID int, name string) {
+	t.Pokedex[dexID] = DexEntry{Name: name, Caught: true}
+}
+
+// Completion counts the seen and caught species with dex numbers between
+// first and last. Caught species count as seen too.
+func (t *Trainer) Completion(first, last int) (seen, caught int) {
+	for id, entry := range t.Pokedex {
+		if id < first || id > last {
+			continue
+		}
+
+		seen++
+		if entry.Caught {
+			caught++
+		}
+	}
+
+	return seen, caught
+}
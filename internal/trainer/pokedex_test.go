@@ -0,0 +1,31 @@
+package trainer
+
+import "testing"
+
+func TestCompletion(t *testing.T) {
+	tr := New()
+	tr.See(1, "bulbasaur")
+	tr.MarkCaught(16, "pidgey")
+	tr.See(16, "pidgey")
+	tr.See(152, "chikorita")
+
+	cases := []struct {
+		first  int
+		last   int
+		seen   int
+		caught int
+	}{
+		{first: 1, last: NationalDexSize, seen: 3, caught: 1},
+		{first: 1, last: 151, seen: 2, caught: 1},
+		{first: 152, last: 251, seen: 1, caught: 0},
+		{first: 252, last: 386, seen: 0, caught: 0},
+	}
+
+	for _, c := range cases {
+		seen, caught := tr.Completion(c.first, c.last)
+		if seen != c.seen || caught != c.caught {
+			t.Errorf("Completion(%d, %d) == %d seen, %d caught, want %d seen, %d caught",
+				c.first, c.last, seen, caught, c.seen, c.caught)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package trainer
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	PartySize = 6
+	BoxSize   = 30
+	BoxCount  = 8
+)
+
+var (
+	ErrPartyFull   = errors.New("your party is full")
+	ErrBoxFull     = errors.New("the box is full")
+	ErrStorageFull = errors.New("your party and all boxes are full")
+	ErrLastPokemon = errors.New("you can't part with your last Pokemon")
+	ErrNoSuchBox   = errors.New("no such box")
+	ErrNoSuchSlot  = errors.New("no Pokemon in that slot")
+)
+
+// Box is a PC box holding up to BoxSize Pokemon.
+type Box struct {
+	Name    string     `json:"name"`
+	Pokemon []*Pokemon `json:"pokemon"`
+}
+
+func newBoxes() []*Box {
+	boxes := make([]*Box, BoxCount)
+	for i := range boxes {
+		boxes[i] = &Box{Name: fmt.Sprintf("Box %d", i+1)}
+	}
+
+	return boxes
+}
+
+// AddPokemon gives a newly caught Pokemon an ID and puts it in the party, or
+// in the first box with room once the party is full. It returns where the
+// Pokemon went.
+func (t *Trainer) AddPokemon(p *Pokemon) (string, error) {
+	if len(t.Party) < PartySize {
+		t.assignID(p)
+		t.Party = append(t.Party, p)
+
+		return "your party", nil
+	}
+
+	for _, box := range t.Boxes {
+		if len(box.Pokemon) < BoxSize {
+			t.assignID(p)
+			box.Pokemon = append(box.Pokemon, p)
+
+			return box.Name, nil
+		}
+	}
+
+	return "", ErrStorageFull
+}
+
+// HasRoom reports whether AddPokemon would find a place for another Pokemon.
+func (t *Trainer) HasRoom() bool {
+	if len(t.Party) < PartySize {
+		return true
+	}
+
+	for _, box := range t.Boxes {
+		if len(box.Pokemon) < BoxSize {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (t *Trainer) assignID(p *Pokemon) {
+	t.LastID++
+	p.ID = t.LastID
+}
+
+// Box returns the box at the zero-based index.
+func (t *Trainer) Box(index int) (*Box, error) {
+	if index < 0 || index >= len(t.Boxes) {
+		return nil, ErrNoSuchBox
+	}
+
+	return t.Boxes[index], nil
+}
+
+// Deposit moves the party Pokemon at slot into the box at boxIndex.
+func (t *Trainer) Deposit(slot, boxIndex int) error {
+	if slot < 0 || slot >= len(t.Party) {
+		return ErrNoSuchSlot
+	}
+
+	if len(t.Party) == 1 {
+		return ErrLastPokemon
+	}
+
+	box, err := t.Box(boxIndex)
+	if err != nil {
+		return err
+	}
+
+	if len(box.Pokemon) >= BoxSize {
+		return ErrBoxFull
+	}
+
+	box.Pokemon = append(box.Pokemon, t.Party[slot])
+	t.Party = append(t.Party[:slot], t.Party[slot+1:]...)
+
+	return nil
+}
+
+// Withdraw moves the Pokemon at slot of the box at boxIndex into the party.
+func (t *Trainer) Withdraw(boxIndex, slot int) error {
+	box, err := t.Box(boxIndex)
+	if err != nil {
+		return err
+	}
+
+	if slot < 0 || slot >= len(box.Pokemon) {
+		return ErrNoSuchSlot
+	}
+
+	if len(t.Party) >= PartySize {
+		return ErrPartyFull
+	}
+
+	t.Party = append(t.Party, box.Pokemon[slot])
+	box.Pokemon = append(box.Pokemon[:slot], box.Pokemon[slot+1:]...)
+
+	return nil
+}
+
+// Swap exchanges two party slots, which is how the lead Pokemon is chosen.
+func (t *Trainer) Swap(a, b int) error {
+	if a < 0 || a >= len(t.Party) || b < 0 || b >= len(t.Party) {
+		return ErrNoSuchSlot
+	}
+
+	t.Party[a], t.Party[b] = t.Party[b], t.Party[a]
+
+	return nil
+}
+
+// ReleaseFromParty lets the party Pokemon at slot go for good.
+func (t *Trainer) ReleaseFromParty(slot int) (*Pokemon, error) {
+	if slot < 0 || slot >= len(t.Party) {
+		return nil, ErrNoSuchSlot
+	}
+
+	if len(t.Party) == 1 {
+		return nil, ErrLastPokemon
+	}
+
+	p := t.Party[slot]
+	t.Party = append(t.Party[:slot], t.Party[slot+1:]...)
+
+	return p, nil
+}
+
+// ReleaseFromBox lets the Pokemon at slot of the box at boxIndex go for good.
+func (t *Trainer) ReleaseFromBox(boxIndex, slot int) (*Pokemon, error) {
+	box, err := t.Box(boxIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	if slot < 0 || slot >= len(box.Pokemon) {
+		return nil, ErrNoSuchSlot
+	}
+
+	p := box.Pokemon[slot]
+	box.Pokemon = append(box.Pokemon[:slot], box.Pokemon[slot+1:]...)
+
+	return p, nil
+}
@@ -0,0 +1,165 @@
+package trainer
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func fillParty(t *Trainer, n int) {
+	for i := 0; i < n; i++ {
+		t.AddPokemon(&Pokemon{Species: fmt.Sprintf("pokemon-%d", i), Level: 5, HP: 20})
+	}
+}
+
+func TestAddPokemonOverflowsToBox(t *testing.T) {
+	tr := New()
+	fillParty(tr, PartySize)
+
+	where, err := tr.AddPokemon(&Pokemon{Species: "pidgey", Level: 3, HP: 15})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if where != "Box 1" {
+		t.Errorf("expected the Pokemon to go to Box 1, got %s", where)
+	}
+
+	if len(tr.Party) != PartySize {
+		t.Errorf("expected party of %d, got %d", PartySize, len(tr.Party))
+	}
+
+	if len(tr.Boxes[0].Pokemon) != 1 || tr.Boxes[0].Pokemon[0].Species != "pidgey" {
+		t.Errorf("expected pidgey in Box 1")
+	}
+}
+
+func TestAddPokemonStorageFull(t *testing.T) {
+	tr := New()
+	fillParty(tr, PartySize+BoxSize*BoxCount-1)
+
+	if !tr.HasRoom() {
+		t.Errorf("expected room for one more Pokemon")
+	}
+
+	fillParty(tr, 1)
+
+	if tr.HasRoom() {
+		t.Errorf("expected no room left")
+	}
+
+	if _, err := tr.AddPokemon(&Pokemon{Species: "pidgey"}); !errors.Is(err, ErrStorageFull) {
+		t.Errorf("expected ErrStorageFull, got %v", err)
+	}
+}
+
+func TestDepositWithdraw(t *testing.T) {
+	tr := New()
+	fillParty(tr, 2)
+
+	if err := tr.Deposit(0, 1); err != nil {
+		t.Fatalf("unexpected error depositing: %v", err)
+	}
+
+	if len(tr.Party) != 1 || tr.Party[0].Species != "pokemon-1" {
+		t.Errorf("expected pokemon-1 to be left in the party")
+	}
+
+	if err := tr.Deposit(0, 1); !errors.Is(err, ErrLastPokemon) {
+		t.Errorf("expected ErrLastPokemon, got %v", err)
+	}
+
+	if err := tr.Withdraw(1, 0); err != nil {
+		t.Fatalf("unexpected error withdrawing: %v", err)
+	}
+
+	if len(tr.Party) != 2 || tr.Party[1].Species != "pokemon-0" {
+		t.Errorf("expected pokemon-0 back at the end of the party")
+	}
+
+	if len(tr.Boxes[1].Pokemon) != 0 {
+		t.Errorf("expected Box 2 to be empty")
+	}
+}
+
+func TestWithdrawPartyFull(t *testing.T) {
+	tr := New()
+	fillParty(tr, PartySize+1)
+
+	if err := tr.Withdraw(0, 0); !errors.Is(err, ErrPartyFull) {
+		t.Errorf("expected ErrPartyFull, got %v", err)
+	}
+}
+
+func TestSwap(t *testing.T) {
+	tr := New()
+	fillParty(tr, 3)
+
+	if err := tr.Swap(0, 2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if tr.Party[0].Species != "pokemon-2" || tr.Party[2].Species != "pokemon-0" {
+		t.Errorf("expected the first and last Pokemon to swap")
+	}
+
+	if err := tr.Swap(0, 3); !errors.Is(err, ErrNoSuchSlot) {
+		t.Errorf("expected ErrNoSuchSlot, got %v", err)
+	}
+}
+
+func TestCloneIsDeep(t *testing.T) {
+	tr := New()
+	fillParty(tr, PartySize+1)
+
+	clone := tr.Clone()
+	clone.Party[0].HP = 0
+	clone.Boxes[0].Pokemon[0].HP = 0
+
+	if tr.Party[0].HP == 0 || tr.Boxes[0].Pokemon[0].HP == 0 {
+		t.Errorf("expected changes to the clone to not affect the original")
+	}
+}
+
+func TestAddPokemonAllowsDuplicates(t *testing.T) {
+	tr := New()
+	tr.AddPokemon(&Pokemon{Species: "pidgey", Level: 3})
+	tr.AddPokemon(&Pokemon{Species: "pidgey", Level: 4})
+
+	matches := tr.PokemonByName("pidgey")
+	if len(matches) != 2 {
+		t.Fatalf("expected 2 pidgey, got %d", len(matches))
+	}
+
+	if matches[0].ID == matches[1].ID {
+		t.Errorf("expected unique IDs, got %d twice", matches[0].ID)
+	}
+
+	if p := tr.PokemonByID(matches[1].ID); p == nil || p.Level != 4 {
+		t.Errorf("expected to find the second pidgey by ID")
+	}
+}
+
+func TestPokemonByName(t *testing.T) {
+	tr := New()
+	tr.AddPokemon(&Pokemon{Species: "pidgey", Nickname: "Sir Flaps"})
+	tr.AddPokemon(&Pokemon{Species: "pidgey"})
+	tr.AddPokemon(&Pokemon{Species: "rattata", Nickname: "Ratón"})
+
+	cases := []struct {
+		name     string
+		expected int
+	}{
+		{name: "pidgey", expected: 2},
+		{name: "Pidgey", expected: 2},
+		{name: "sir flaps", expected: 1},
+		{name: "RATÓN", expected: 1},
+		{name: "Flaps", expected: 0},
+	}
+
+	for _, c := range cases {
+		if actual := tr.PokemonByName(c.name); len(actual) != c.expected {
+			t.Errorf("PokemonByName(%q) found %d, want %d", c.name, len(actual), c.expected)
+		}
+	}
+}
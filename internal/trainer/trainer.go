@@ -0,0 +1,291 @@
+package trainer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ArturM94/pokedexcli/internal/atomicfile"
+	"github.com/ArturM94/pokedexcli/internal/battle"
+)
+
+type Trainer struct {
+	Money int            `json:"money"`
+	Bag   map[string]int `json:"bag"`
+	Party []*Pokemon     `json:"party"`
+	Boxes []*Box         `json:"boxes"`
+	// Pokedex holds the species seen or caught, keyed by national dex number.
+	Pokedex map[int]DexEntry `json:"pokedex"`
+	// LastID is the most recently assigned Pokemon ID.
+	LastID int `json:"last_id"`
+}
+
+// Pokemon is a caught Pokemon owned by the trainer. ID tells apart several
+// Pokemon of the same species.
+type Pokemon struct {
+	ID      int    `json:"id"`
+	Species string `json:"species"`
+	Level   int    `json:"level"`
+	// Experience is the total experience earned, which sets the level
+	// according to the species growth rate.
+	Experience int           `json:"experience"`
+	HP         int           `json:"hp"`
+	Moves      []Move        `json:"moves"`
+	Gender     string        `json:"gender"`
+	Nature     battle.Nature `json:"nature"`
+	IVs        battle.Stats  `json:"ivs"`
+	EVs        battle.Stats  `json:"evs"`
+	Shiny      bool          `json:"shiny"`
+	Nickname   string        `json:"nickname,omitempty"`
+}
+
+type Move struct {
+	Name  string `json:"name"`
+	PP    int    `json:"pp"`
+	MaxPP int    `json:"max_pp"`
+}
+
+func New() *Trainer {
+	return &Trainer{
+		Money: 3000,
+		Bag: map[string]int{
+			"poke-ball": 10,
+			"potion":    3,
+		},
+		Boxes:   newBoxes(),
+		Pokedex: map[int]DexEntry{},
+	}
+}
+
+func Load(path string) (*Trainer, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return New(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading save file: %w", err)
+	}
+
+	var t Trainer
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("error parsing save file: %w", err)
+	}
+
+	if t.Bag == nil {
+		t.Bag = map[string]int{}
+	}
+
+	if len(t.Boxes) == 0 {
+		t.Boxes = newBoxes()
+	}
+
+	if t.Pokedex == nil {
+		t.Pokedex = map[int]DexEntry{}
+	}
+
+	// Saves from before Pokemon had IDs get them assigned on load.
+	for _, p := range t.AllPokemon() {
+		if p.ID == 0 {
+			t.LastID++
+			p.ID = t.LastID
+		}
+	}
+
+	return &t, nil
+}
+
+// Save writes the trainer to path, replacing any previous save atomically.
+func (t *Trainer) Save(path string) error {
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding save file: %w", err)
+	}
+
+	if err := atomicfile.WriteFile(path, data); err != nil {
+		return fmt.Errorf("error writing save file: %w", err)
+	}
+
+	return nil
+}
+
+// Transact applies fn to a copy of the trainer and saves it to path. The
+// trainer is only updated once the save succeeds, so a failed fn or a failed
+// write leaves both the in-memory and the saved state untouched.
+func (t *Trainer) Transact(path string, fn func(*Trainer) error) error {
+	next := t.Clone()
+
+	if err := fn(next); err != nil {
+		return err
+	}
+
+	if err := next.Save(path); err != nil {
+		return err
+	}
+
+	*t = *next
+
+	return nil
+}
+
+func (t *Trainer) Clone() *Trainer {
+	clone := *t
+
+	clone.Bag = make(map[string]int, len(t.Bag))
+	for name, qty := range t.Bag {
+		clone.Bag[name] = qty
+	}
+
+	clone.Pokedex = make(map[int]DexEntry, len(t.Pokedex))
+	for id, entry := range t.Pokedex {
+		clone.Pokedex[id] = entry
+	}
+
+	clone.Party = make([]*Pokemon, len(t.Party))
+	for i, p := range t.Party {
+		clone.Party[i] = p.Clone()
+	}
+
+	clone.Boxes = make([]*Box, len(t.Boxes))
+	for i, box := range t.Boxes {
+		boxClone := &Box{Name: box.Name, Pokemon: make([]*Pokemon, len(box.Pokemon))}
+		for j, p := range box.Pokemon {
+			boxClone.Pokemon[j] = p.Clone()
+		}
+
+		clone.Boxes[i] = boxClone
+	}
+
+	return &clone
+}
+
+func (p *Pokemon) Clone() *Pokemon {
+	clone := *p
+	clone.Moves = append([]Move(nil), p.Moves...)
+
+	return &clone
+}
+
+const (
+	MaxEV      = 252
+	MaxTotalEV = 510
+)
+
+// GainEVs adds the effort values earned from defeating a Pokemon, capped at
+// MaxEV per stat and MaxTotalEV overall.
+func (p *Pokemon) GainEVs(yield battle.Stats) {
+	evs := []*int{&p.EVs.HP, &p.EVs.Attack, &p.EVs.Defense, &p.EVs.SpecialAttack, &p.EVs.SpecialDefense, &p.EVs.Speed}
+	gains := []int{yield.HP, yield.Attack, yield.Defense, yield.SpecialAttack, yield.SpecialDefense, yield.Speed}
+
+	total := 0
+	for _, ev := range evs {
+		total += *ev
+	}
+
+	for i, ev := range evs {
+		gain := min(gains[i], MaxEV-*ev, MaxTotalEV-total)
+		if gain <= 0 {
+			continue
+		}
+
+		*ev += gain
+		total += gain
+	}
+}
+
+// MaxNicknameLength is the longest nickname allowed, in characters.
+const MaxNicknameLength = 20
+
+var ErrInvalidNickname = errors.New("nicknames must be printable, at most 20 characters and not a number")
+
+// ValidateNickname trims a nickname and checks it can be shown safely.
+// Spaces and any Unicode letters or symbols are allowed, but not a number
+// such as 12 or #3, which would be taken for a Pokemon ID.
+func ValidateNickname(nickname string) (string, error) {
+	nickname = strings.TrimSpace(nickname)
+
+	if utf8.RuneCountInString(nickname) > MaxNicknameLength {
+		return "", ErrInvalidNickname
+	}
+
+	for _, r := range nickname {
+		if !unicode.IsPrint(r) {
+			return "", ErrInvalidNickname
+		}
+	}
+
+	if _, err := strconv.Atoi(strings.TrimPrefix(nickname, "#")); err == nil {
+		return "", ErrInvalidNickname
+	}
+
+	return nickname, nil
+}
+
+// Name is the nickname of the Pokemon, or its species when it has none.
+func (p *Pokemon) Name() string {
+	if p.Nickname != "" {
+		return p.Nickname
+	}
+
+	return p.Species
+}
+
+// AllPokemon returns the party followed by the contents of every box.
+func (t *Trainer) AllPokemon() []*Pokemon {
+	all := append([]*Pokemon(nil), t.Party...)
+	for _, box := range t.Boxes {
+		all = append(all, box.Pokemon...)
+	}
+
+	return all
+}
+
+func (t *Trainer) PokemonByID(id int) *Pokemon {
+	for _, p := range t.AllPokemon() {
+		if p.ID == id {
+			return p
+		}
+	}
+
+	return nil
+}
+
+// PokemonByName returns every owned Pokemon whose species or nickname is
+// name. Names match regardless of case.
+func (t *Trainer) PokemonByName(name string) []*Pokemon {
+	var matches []*Pokemon
+	for _, p := range t.AllPokemon() {
+		if strings.EqualFold(p.Species, name) || (p.Nickname != "" && strings.EqualFold(p.Nickname, name)) {
+			matches = append(matches, p)
+		}
+	}
+
+	return matches
+}
+
+func (t *Trainer) ItemCount(name string) int {
+	return t.Bag[name]
+}
+
+func (t *Trainer) AddItem(name string, qty int) {
+	t.Bag[name] += qty
+}
+
+// RemoveItem takes qty of the item out of the bag. It reports false and
+// leaves the bag untouched when there are not enough of them.
+func (t *Trainer) RemoveItem(name string, qty int) bool {
+	if t.Bag[name] < qty {
+		return false
+	}
+
+	t.Bag[name] -= qty
+	if t.Bag[name] == 0 {
+		delete(t.Bag, name)
+	}
+
+	return true
+}
@@ -0,0 +1,192 @@
+package trainer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ArturM94/pokedexcli/internal/battle"
+)
+
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "save.json")
+
+	saved := New()
+	saved.AddItem("great-ball", 2)
+
+	if err := saved.Save(path); err != nil {
+		t.Fatalf("unexpected error saving: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error loading: %v", err)
+	}
+
+	for name, qty := range saved.Bag {
+		if loaded.ItemCount(name) != qty {
+			t.Errorf("expected %d %s, got %d", qty, name, loaded.ItemCount(name))
+		}
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	loaded, err := Load(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil {
+		t.Fatalf("unexpected error loading: %v", err)
+	}
+
+	if loaded.ItemCount("poke-ball") == 0 {
+		t.Errorf("expected a new trainer to start with Poke Balls")
+	}
+}
+
+func TestRemoveItem(t *testing.T) {
+	cases := []struct {
+		have     int
+		remove   int
+		expected bool
+		left     int
+	}{
+		{have: 3, remove: 1, expected: true, left: 2},
+		{have: 1, remove: 1, expected: true, left: 0},
+		{have: 1, remove: 2, expected: false, left: 1},
+		{have: 0, remove: 1, expected: false, left: 0},
+	}
+
+	for _, c := range cases {
+		tr := &Trainer{Bag: map[string]int{}}
+		tr.AddItem("potion", c.have)
+
+		if ok := tr.RemoveItem("potion", c.remove); ok != c.expected {
+			t.Errorf("RemoveItem(%d) with %d == %v, want %v", c.remove, c.have, ok, c.expected)
+		}
+
+		if tr.ItemCount("potion") != c.left {
+			t.Errorf("expected %d left, got %d", c.left, tr.ItemCount("potion"))
+		}
+	}
+}
+
+func TestTransactRollback(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "save.json")
+	tr := New()
+	money := tr.Money
+
+	err := tr.Transact(path, func(next *Trainer) error {
+		next.Money -= 100
+		next.AddItem("great-ball", 1)
+
+		return errors.New("not enough money")
+	})
+	if err == nil {
+		t.Fatalf("expected error from transaction")
+	}
+
+	if tr.Money != money {
+		t.Errorf("expected money to stay %d, got %d", money, tr.Money)
+	}
+
+	if tr.ItemCount("great-ball") != 0 {
+		t.Errorf("expected no great-ball after rollback")
+	}
+
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected save file to not be written")
+	}
+}
+
+func TestTransactCommit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "save.json")
+	tr := New()
+	money := tr.Money
+
+	err := tr.Transact(path, func(next *Trainer) error {
+		next.Money -= 200
+		next.AddItem("great-ball", 1)
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error loading: %v", err)
+	}
+
+	if tr.Money != money-200 || loaded.Money != money-200 {
+		t.Errorf("expected money %d, got %d in memory and %d saved", money-200, tr.Money, loaded.Money)
+	}
+
+	if loaded.ItemCount("great-ball") != 1 {
+		t.Errorf("expected great-ball to be saved")
+	}
+}
+
+func TestGainEVs(t *testing.T) {
+	cases := []struct {
+		have     battle.Stats
+		yield    battle.Stats
+		expected battle.Stats
+	}{
+		{
+			yield:    battle.Stats{Speed: 1},
+			expected: battle.Stats{Speed: 1},
+		},
+		{
+			have:     battle.Stats{Attack: 251},
+			yield:    battle.Stats{Attack: 2, Speed: 1},
+			expected: battle.Stats{Attack: 252, Speed: 1},
+		},
+		{
+			have:     battle.Stats{Attack: 252, Speed: 252, HP: 5},
+			yield:    battle.Stats{HP: 3, Defense: 2},
+			expected: battle.Stats{Attack: 252, Speed: 252, HP: 6},
+		},
+	}
+
+	for _, c := range cases {
+		p := &Pokemon{EVs: c.have}
+		p.GainEVs(c.yield)
+
+		if p.EVs != c.expected {
+			t.Errorf("GainEVs(%+v) on %+v == %+v, want %+v", c.yield, c.have, p.EVs, c.expected)
+		}
+	}
+}
+
+func TestValidateNickname(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected string
+		err      bool
+	}{
+		{input: "Sparky", expected: "Sparky"},
+		{input: "  Sir Flaps  ", expected: "Sir Flaps"},
+		{input: "ピカチュウ", expected: "ピカチュウ"},
+		{input: "", expected: ""},
+		{input: "a name that is far too long", err: true},
+		{input: "bad\x1b[31mname", err: true},
+		{input: "12", err: true},
+		{input: "#3", err: true},
+		{input: " 7 ", err: true},
+		{input: "Route 1", expected: "Route 1"},
+	}
+
+	for _, c := range cases {
+		actual, err := ValidateNickname(c.input)
+		if c.err {
+			if err == nil {
+				t.Errorf("ValidateNickname(%q) expected an error", c.input)
+			}
+			continue
+		}
+
+		if err != nil || actual != c.expected {
+			t.Errorf("ValidateNickname(%q) == %q, %v, want %q", c.input, actual, err, c.expected)
+		}
+	}
+}
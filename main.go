@@ -2,33 +2,101 @@ package main
 
 import (
 	"bufio"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
-	"math/rand"
+	"io"
+	"math/rand/v2"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/ArturM94/pokedexcli/internal/pokeapi"
 	"github.com/ArturM94/pokedexcli/internal/pokecache"
+	"github.com/ArturM94/pokedexcli/internal/render"
+	"github.com/ArturM94/pokedexcli/internal/search"
+	"github.com/ArturM94/pokedexcli/internal/trainer"
 )
 
+// cleanInput splits a command line into words the way a shell would: words
+// are separated by any whitespace, single and double quotes group text into
+// one word and a backslash escapes the next character (inside double quotes
+// too). An unterminated quote runs to the end of the line. Case is kept, so
+// commands lowercase the names they look up with resourceName.
 func cleanInput(text string) []string {
-	trimmed := strings.TrimSpace(text)
-	splitted := strings.Split(trimmed, " ")
-	var filtered []string
-
-	for _, s := range splitted {
-		if s == "" {
-			continue
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+
+	for _, r := range text {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inWord = true
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			word.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote = r
+			inWord = true
+		case unicode.IsSpace(r):
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
 		}
+	}
 
-		filtered = append(filtered, s)
+	if inWord {
+		words = append(words, word.String())
 	}
 
-	return filtered
+	return words
+}
+
+// resourceName turns a name as typed, e.g. Pikachu, into the lowercase form
+// the API uses for commands and resources.
+func resourceName(name string) string {
+	return strings.ToLower(name)
+}
+
+// parseFlags parses flags that may appear before, after or between the
+// positional arguments, which it returns in order.
+func parseFlags(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+
+		if flags.NArg() == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
 }
 
 func commandExit(ctx *commandContext) error {
+	if err := ctx.Trainer.Save(ctx.SavePath); err != nil {
+		return fmt.Errorf("error saving trainer: %w", err)
+	}
+
 	fmt.Println("Closing the Pokedex... Goodbye!")
 	os.Exit(0)
 	return nil
@@ -49,51 +117,135 @@ func commandHelp(ctx *commandContext) error {
 	return nil
 }
 
+const mapUsage = "usage: map [first|last] [--page <n>] [--limit <n>]"
+
 func commandMap(ctx *commandContext) error {
-	locations, err := pokeapi.GetLocationAreas(ctx.Cache, ctx.Config.Next)
-	if err != nil {
-		return fmt.Errorf("error getting location areas: %w", err)
+	flags := flag.NewFlagSet("map", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	page := flags.Int("page", 0, "")
+	limit := flags.Int("limit", 0, "")
+
+	args, err := parseFlags(flags, ctx.Args)
+	if err != nil || *page < 0 || *limit < 0 {
+		return errors.New(mapUsage)
 	}
 
-	ctx.Config.Next = locations.Next
-	ctx.Config.Previous = locations.Previous
+	state := &ctx.Config.Map
+	if *limit > 0 {
+		state.resize(*limit)
+	}
 
-	for _, location := range locations.Results {
-		fmt.Println(location.Name)
+	target := state.Page + 1
+	if *page > 0 {
+		target = *page
 	}
 
-	return nil
+	switch {
+	case len(args) == 0:
+	case len(args) == 1 && resourceName(args[0]) == "first":
+		target = 1
+	case len(args) == 1 && resourceName(args[0]) == "last":
+		// The number of pages is only known once a page has been fetched.
+		if state.Count == 0 {
+			if _, err := fetchLocationAreas(ctx, 1); err != nil {
+				return err
+			}
+		}
+
+		target = state.pages()
+	default:
+		return errors.New(mapUsage)
+	}
+
+	if state.Count > 0 && target > state.pages() {
+		if len(ctx.Args) == 0 {
+			fmt.Println("you're on the last page")
+
+			return nil
+		}
+
+		return fmt.Errorf("page %d is out of range 1-%d", target, state.pages())
+	}
+
+	return showLocationAreas(ctx, target)
 }
 
 func commandMapb(ctx *commandContext) error {
-	if ctx.Config.Previous == nil {
+	state := &ctx.Config.Map
+	if state.Page <= 1 {
 		fmt.Println("you're on the first page")
 		return nil
 	}
 
-	locations, err := pokeapi.GetLocationAreas(ctx.Cache, ctx.Config.Previous)
+	return showLocationAreas(ctx, state.Page-1)
+}
+
+func showLocationAreas(ctx *commandContext, page int) error {
+	locations, err := fetchLocationAreas(ctx, page)
 	if err != nil {
-		return fmt.Errorf("error getting location areas: %w", err)
+		return err
 	}
 
-	ctx.Config.Next = locations.Next
-	ctx.Config.Previous = locations.Previous
+	state := &ctx.Config.Map
+	if len(locations.Results) == 0 {
+		return fmt.Errorf("page %d is out of range 1-%d", page, state.pages())
+	}
+	state.Page = page
 
 	for _, location := range locations.Results {
 		fmt.Println(location.Name)
 	}
+	fmt.Printf("Page %d of %d\n", state.Page, state.pages())
 
 	return nil
 }
 
+func fetchLocationAreas(ctx *commandContext, page int) (*pokeapi.GetLocationAreasResponse, error) {
+	state := &ctx.Config.Map
+
+	locations, err := ctx.Client.GetLocationAreaPage((page-1)*state.limit(), state.limit())
+	if err != nil {
+		return nil, fmt.Errorf("error getting location areas: %w", err)
+	}
+	state.Count = locations.Count
+
+	return locations, nil
+}
+
+// prefetchEncounters loads the Pokemon found by explore in the background,
+// so catching or battling one doesn't wait on the network. Exploring again
+// cancels what's left of the previous location's prefetch.
+func prefetchEncounters(ctx *commandContext) {
+	if ctx.Config.StopPrefetch != nil {
+		ctx.Config.StopPrefetch()
+	}
+
+	names := make([]string, len(ctx.Config.Encounters))
+	for i, e := range ctx.Config.Encounters {
+		names[i] = e.Name
+	}
+
+	prefetch, stop := context.WithCancel(context.Background())
+	ctx.Client.PrefetchPokemon(prefetch, names, prefetchWorkers)
+	ctx.Config.StopPrefetch = stop
+}
+
 func commandExplore(ctx *commandContext) error {
+	name, ok := correctName(ctx, "location-area", ctx.LocationName)
+	if !ok {
+		return nil
+	}
+	ctx.LocationName = name
+
 	fmt.Println("Exploring " + ctx.LocationName + "...")
 
-	locationDetails, err := pokeapi.GetLocationAreaDetails(ctx.Cache, ctx.LocationName)
+	locationDetails, err := ctx.Client.GetLocationAreaDetails(ctx.LocationName)
 	if err != nil {
 		return fmt.Errorf("error getting location detals: %w", err)
 	}
 
+	indexLocalized(ctx, "location-area", locationDetails.Name, locationDetails.Names)
+
 	if len(locationDetails.PokemonEncounters) == 0 {
 		fmt.Println("Pokemon not found")
 
@@ -102,29 +254,165 @@ func commandExplore(ctx *commandContext) error {
 
 	fmt.Println("Found Pokemon:")
 
+	ctx.Config.Encounters = nil
 	for _, pokemonEncounter := range locationDetails.PokemonEncounters {
 		fmt.Println(" - " + pokemonEncounter.Pokemon.Name)
+
+		dexID, err := pokeapi.IDFromURL(pokemonEncounter.Pokemon.URL)
+		if err != nil {
+			return err
+		}
+
+		// Alternate forms have IDs of their own past the National Pokedex,
+		// so their number comes from their species.
+		if dexID > trainer.NationalDexSize {
+			pokemon, err := ctx.Client.GetPokemon(pokemonEncounter.Pokemon.Name)
+			if err != nil {
+				return fmt.Errorf("error getting pokemon: %w", err)
+			}
+
+			dexID, err = dexNumber(pokemon)
+			if err != nil {
+				return err
+			}
+		}
+
+		e := encounter{Name: pokemonEncounter.Pokemon.Name, DexID: dexID, MinLevel: 100, MaxLevel: 1}
+		for _, version := range pokemonEncounter.VersionDetails {
+			for _, detail := range version.EncounterDetails {
+				e.MinLevel = min(e.MinLevel, detail.MinLevel)
+				e.MaxLevel = max(e.MaxLevel, detail.MaxLevel)
+			}
+		}
+		if e.MinLevel > e.MaxLevel {
+			e.MinLevel, e.MaxLevel = 5, 5
+		}
+
+		ctx.Config.Encounters = append(ctx.Config.Encounters, e)
+	}
+
+	prefetchEncounters(ctx)
+
+	err = ctx.Trainer.Transact(ctx.SavePath, func(t *trainer.Trainer) error {
+		for _, e := range ctx.Config.Encounters {
+			t.See(e.DexID, e.Name)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error saving trainer: %w", err)
 	}
 
 	return nil
 }
 
 func commandCatch(ctx *commandContext) error {
-	fmt.Println("Throwing a Pokeball at " + ctx.PokemonName + "...")
+	ball := "poke-ball"
+	if len(ctx.Args) >= 2 {
+		ball = resourceName(ctx.Args[1])
+	}
 
-	pokemon, err := pokeapi.GetPokemon(ctx.Cache, ctx.PokemonName)
+	modifier, ok := ballModifiers[ball]
+	if !ok {
+		fmt.Println(ball + " is not a Poke Ball!")
+
+		return nil
+	}
+
+	if ctx.Trainer.ItemCount(ball) == 0 {
+		fmt.Println("You don't have any " + ball + "!")
+
+		return nil
+	}
+
+	if !ctx.Trainer.HasRoom() {
+		fmt.Println("Your party and all boxes are full! Release a Pokemon to make room.")
+
+		return nil
+	}
+
+	active := ctx.Config.Battle
+	if active != nil && ctx.PokemonName == "" {
+		ctx.PokemonName = active.wild.Species
+	}
+
+	name, ok := correctName(ctx, "pokemon", ctx.PokemonName)
+	if !ok {
+		return nil
+	}
+	ctx.PokemonName = name
+
+	if active != nil && ctx.PokemonName != active.wild.Species {
+		fmt.Println("You can only catch the wild " + active.wild.Species + " you're battling.")
+
+		return nil
+	}
+
+	pokemon, err := ctx.Client.GetPokemon(ctx.PokemonName)
 	if err != nil {
 		return fmt.Errorf("error catching pokemon: %w", err)
 	}
 
-	rand.Seed(time.Now().UnixNano())
-	chance := rand.Intn(100)
-	catchRate := 100 - (pokemon.BaseExperience / 3)
+	fmt.Println("Throwing a " + ball + " at " + ctx.PokemonName + "...")
+
+	caught := isCaught(ctx.Rand, pokemon.BaseExperience, modifier)
+	reward := catchReward(pokemon.BaseExperience)
+
+	var caughtPokemon *trainer.Pokemon
+	switch {
+	case caught && active != nil:
+		// It's the Pokemon that appeared, with the HP it has left.
+		caughtPokemon = active.wild
+		caughtPokemon.HP = active.fight.Wild.HP
+	case caught:
+		caughtPokemon, err = newTrainerPokemon(ctx, pokemon, encounterLevel(ctx, pokemon.Name))
+		if err != nil {
+			return err
+		}
+	}
+
+	dexID, err := dexNumber(pokemon)
+	if err != nil {
+		return err
+	}
+
+	var sentTo string
+	err = ctx.Trainer.Transact(ctx.SavePath, func(t *trainer.Trainer) error {
+		t.RemoveItem(ball, 1)
+		t.See(dexID, pokemon.Name)
+		if !caught {
+			return nil
+		}
+
+		var err error
+		t.MarkCaught(dexID, pokemon.Name)
+		t.Money += reward
+		sentTo, err = t.AddPokemon(caughtPokemon)
 
-	if chance < catchRate {
-		fmt.Println(pokemon.Name + " was caught!")
-		pokedex[pokemon.Name] = pokemon
-		fmt.Println("You may now inspect it with the inspect command.")
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("error saving trainer: %w", err)
+	}
+
+	if caught && active != nil {
+		ctx.Config.Battle = nil
+	}
+
+	if caught {
+		if caughtPokemon.Shiny {
+			fmt.Println("Wow, it's a shiny " + pokemon.Name + "!")
+		}
+		fmt.Printf("%s (Lv. %d) was caught!\n", pokemon.Name, caughtPokemon.Level)
+		fmt.Printf("%s was sent to %s.\n", pokemon.Name, sentTo)
+		fmt.Printf("You earned $%d.\n", reward)
+		fmt.Printf("You may now inspect it with: inspect #%d\n", caughtPokemon.ID)
+
+		nickname, ok := prompt(ctx, "Give "+pokemon.Name+" a nickname? (leave empty to skip): ")
+		if ok && nickname != "" {
+			return setNickname(ctx, caughtPokemon, nickname)
+		}
 	} else {
 		fmt.Println(pokemon.Name + " escaped!")
 	}
@@ -132,21 +420,74 @@ func commandCatch(ctx *commandContext) error {
 	return nil
 }
 
+func isCaught(rng *rand.Rand, baseExperience int, ballModifier float64) bool {
+	// The Master Ball never fails.
+	if ballModifier >= ballModifiers["master-ball"] {
+		return true
+	}
+
+	chance := rng.IntN(100)
+	catchRate := int(float64(100-(baseExperience/3)) * ballModifier)
+
+	return chance < catchRate
+}
+
 func commandInspect(ctx *commandContext) error {
-	pokemon, ok := pokedex[ctx.PokemonName]
-	if !ok {
-		fmt.Println("You has not caught " + ctx.PokemonName + "!")
+	flags := flag.NewFlagSet("inspect", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	showSprite := flags.Bool("sprite", false, "")
+
+	args, err := parseFlags(flags, ctx.Args)
+	if err != nil || len(args) == 0 {
+		fmt.Println("usage: inspect <pokemon> [--sprite]")
 
 		return nil
 	}
 
+	owned, err := resolvePokemon(ctx, strings.Join(args, " "))
+	if err != nil || owned == nil {
+		return err
+	}
+
+	pokemon, err := ctx.Client.GetPokemon(owned.Species)
+	if err != nil {
+		return fmt.Errorf("error getting pokemon: %w", err)
+	}
+
+	stats := pokemonStats(pokemon, owned)
+
+	fmt.Println("ID:", owned.ID)
 	fmt.Println("Name:", pokemon.Name)
+	if owned.Nickname != "" {
+		fmt.Println("Nickname:", owned.Nickname)
+	}
+	fmt.Println("Level:", owned.Level)
+	fmt.Println("Experience:", owned.Experience)
+	fmt.Printf("HP: %d/%d\n", owned.HP, stats.HP)
+	fmt.Println("Gender:", owned.Gender)
+	if owned.Shiny {
+		fmt.Println("Shiny: yes")
+	}
+	fmt.Println("Nature:", describeNature(owned.Nature))
 	fmt.Println("Height:", pokemon.Height)
-	fmt.Println("Weight: ", pokemon.Weight)
+	fmt.Println("Weight:", pokemon.Weight)
+
+	base := baseStats(pokemon)
+	rows := []struct {
+		name                 string
+		base, iv, ev, actual int
+	}{
+		{"hp", base.HP, owned.IVs.HP, owned.EVs.HP, stats.HP},
+		{"attack", base.Attack, owned.IVs.Attack, owned.EVs.Attack, stats.Attack},
+		{"defense", base.Defense, owned.IVs.Defense, owned.EVs.Defense, stats.Defense},
+		{"special-attack", base.SpecialAttack, owned.IVs.SpecialAttack, owned.EVs.SpecialAttack, stats.SpecialAttack},
+		{"special-defense", base.SpecialDefense, owned.IVs.SpecialDefense, owned.EVs.SpecialDefense, stats.SpecialDefense},
+		{"speed", base.Speed, owned.IVs.Speed, owned.EVs.Speed, stats.Speed},
+	}
 
-	fmt.Println("Weight:")
-	for _, stat := range pokemon.Stats {
-		fmt.Println("  -"+stat.Stat.Name+":", stat.BaseStat)
+	fmt.Println("Stats:")
+	for _, row := range rows {
+		fmt.Printf("  -%s: %d (base %d, IV %d, EV %d)\n", row.name, row.actual, row.base, row.iv, row.ev)
 	}
 
 	fmt.Println("Types:")
@@ -154,20 +495,27 @@ func commandInspect(ctx *commandContext) error {
 		fmt.Println(" - " + typ.Type.Name)
 	}
 
-	return nil
-}
+	if *showSprite {
+		url, err := spriteURL(pokemon, spriteOptions{shiny: owned.Shiny})
+		if err != nil {
+			return err
+		}
 
-func commandPokedex(ctx *commandContext) error {
-	for _, pokemon := range pokedex {
-		fmt.Println(" - " + pokemon.Name)
+		return printSprite(ctx, url, render.DetectMode(os.Getenv))
 	}
 
 	return nil
 }
 
 type commandContext struct {
-	Cache        *pokecache.Cache
+	Input        *bufio.Scanner
+	Client       *pokeapi.Client
+	Rand         *rand.Rand
 	Config       *cliConfig
+	Trainer      *trainer.Trainer
+	SavePath     string
+	IndexPath    string
+	Args         []string
 	LocationName string
 	PokemonName  string
 }
@@ -179,14 +527,126 @@ type cliCommand struct {
 }
 
 type cliConfig struct {
-	Next     *string
-	Previous *string
+	Map        listPage
+	Encounters []encounter
+	Battle     *activeBattle
+	// ShinyOdds is the 1 in N chance of an encountered Pokemon being shiny.
+	ShinyOdds int
+	// AutoCorrect looks up the closest name instead of a mistyped one when
+	// there is a clear winner.
+	AutoCorrect bool
+	Index       *search.Index
+	// StopPrefetch cancels the prefetch started by the last explore.
+	StopPrefetch context.CancelFunc
+	// Interactive is set when the input is a terminal, so commands may ask
+	// follow-up questions.
+	Interactive bool
 }
 
 var commands map[string]cliCommand
-var pokedex = map[string]*pokeapi.GetPokemonResponse{}
+
+const defaultPageSize = 20
+
+// prefetchWorkers is how many requests explore's prefetch makes at once.
+const prefetchWorkers = 4
+
+// cacheTTL is how long API responses are kept when the API doesn't say. It
+// is long enough for prefetched Pokemon to still be there when they are
+// caught.
+const cacheTTL = 5 * time.Minute
+
+// cacheReapInterval is how often expired responses are dropped.
+const cacheReapInterval = time.Minute
+
+// Expired responses are used while they are refreshed for up to
+// cacheStaleWhileRevalidate, and when the API can't be reached for up to
+// cacheMaxStale.
+const (
+	cacheStaleWhileRevalidate = time.Hour
+	cacheMaxStale             = 7 * 24 * time.Hour
+)
+
+// Limits on the response cache. A Pokemon is a few hundred KB of JSON, so
+// the byte limit is usually reached first.
+const (
+	cacheMaxEntries = 2000
+	cacheMaxBytes   = 64 << 20
+)
+
+// listPage is where the user is in a list shown a page at a time.
+type listPage struct {
+	// Page is the last page shown, counting from 1, or 0 before the first.
+	Page  int
+	Limit int
+	// Count is the number of items in the whole list, once known.
+	Count int
+}
+
+func (p *listPage) limit() int {
+	if p.Limit == 0 {
+		return defaultPageSize
+	}
+	return p.Limit
+}
+
+func (p *listPage) pages() int {
+	return pageCount(p.Count, p.limit())
+}
+
+// resize changes the page size, keeping roughly the same position in the
+// list: the next page is the one with the new size that contains the item
+// the next page would have started with before. Pages start on multiples of
+// the size, so it may repeat some items that were already shown.
+func (p *listPage) resize(limit int) {
+	next := p.Page * p.limit()
+	p.Limit = limit
+	p.Page = next / limit
+}
+
+func pageCount(count, limit int) int {
+	return max(1, (count+limit-1)/limit)
+}
+
+// isTerminal reports whether f is a terminal rather than a file or pipe.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+func newRand(seed uint64) *rand.Rand {
+	return rand.New(rand.NewPCG(seed, seed))
+}
+
+func defaultSavePath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "pokedex-save.json"
+	}
+
+	return filepath.Join(dir, "pokedexcli", "save.json")
+}
+
+func defaultIndexPath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "pokedex-search-index.json"
+	}
+
+	return filepath.Join(dir, "pokedexcli", "search-index.json")
+}
 
 func main() {
+	seed := flag.Uint64("seed", uint64(time.Now().UnixNano()), "seed for the random number generator")
+	savePath := flag.String("save", defaultSavePath(), "path to the save file")
+	shinyOdds := flag.Int("shiny-odds", 4096, "1 in how many encountered Pokemon are shiny (1 makes every Pokemon shiny)")
+	indexPath := flag.String("index", defaultIndexPath(), "path to the search index of Pokemon, move, item and location names")
+	autoCorrect := flag.Bool("autocorrect", false, "use the closest Pokemon, move or location name when one is mistyped")
+	flag.Parse()
+
 	commands = map[string]cliCommand{
 		"help": {
 			name:        "help",
@@ -200,7 +660,7 @@ func main() {
 		},
 		"map": {
 			name:        "map",
-			description: "Paginates over Pokemon maps",
+			description: "Paginates over Pokemon maps (map [first|last] [--page <n>] [--limit <n>])",
 			callback:    commandMap,
 		},
 		"mapb": {
@@ -225,13 +685,145 @@ func main() {
 		},
 		"pokedex": {
 			name:        "pokedex",
-			description: "Shows all caught pokemons",
+			description: "Shows Pokedex completion and every species seen or caught",
 			callback:    commandPokedex,
 		},
+		"bag": {
+			name:        "bag",
+			description: "Shows the items in your bag",
+			callback:    commandBag,
+		},
+		"use": {
+			name:        "use",
+			description: "Use an item from your bag",
+			callback:    commandUse,
+		},
+		"item": {
+			name:        "item",
+			description: "Shows details about an item",
+			callback:    commandItem,
+		},
+		"battle": {
+			name:        "battle",
+			description: "Battle a wild Pokemon from the explored area",
+			callback:    commandBattle,
+		},
+		"attack": {
+			name:        "attack",
+			description: "Use a move in the current battle",
+			callback:    commandAttack,
+		},
+		"run": {
+			name:        "run",
+			description: "Run away from the current battle",
+			callback:    commandRun,
+		},
+		"heal": {
+			name:        "heal",
+			description: "Restore your Pokemon at the Pokemon Center",
+			callback:    commandHeal,
+		},
+		"party": {
+			name:        "party",
+			description: "Shows the Pokemon in your party",
+			callback:    commandParty,
+		},
+		"box": {
+			name:        "box",
+			description: "Shows the Pokemon in a PC box",
+			callback:    commandBox,
+		},
+		"deposit": {
+			name:        "deposit",
+			description: "Move a party Pokemon into a PC box",
+			callback:    commandDeposit,
+		},
+		"withdraw": {
+			name:        "withdraw",
+			description: "Move a Pokemon from a PC box into your party",
+			callback:    commandWithdraw,
+		},
+		"swap": {
+			name:        "swap",
+			description: "Swap the order of two party Pokemon",
+			callback:    commandSwap,
+		},
+		"release": {
+			name:        "release",
+			description: "Release a Pokemon from your party or a PC box",
+			callback:    commandRelease,
+		},
+		"sprite": {
+			name:        "sprite",
+			description: "Draws a Pokemon sprite in the terminal",
+			callback:    commandSprite,
+		},
+		"cache": {
+			name:        "cache",
+			description: "Shows and manages the API response cache (cache stats|list|clear|get <key>)",
+			callback:    commandCache,
+		},
+		"search": {
+			name:        "search",
+			description: "Search Pokemon, moves, items and locations by name",
+			callback:    commandSearch,
+		},
+		"nickname": {
+			name:        "nickname",
+			description: "Give one of your Pokemon a nickname",
+			callback:    commandNickname,
+		},
+		"money": {
+			name:        "money",
+			description: "Shows how much money you have",
+			callback:    commandMoney,
+		},
+		"shop": {
+			name:        "shop",
+			description: "Lists the items sold at the Poke Mart",
+			callback:    commandShop,
+		},
+		"buy": {
+			name:        "buy",
+			description: "Buy an item from the Poke Mart",
+			callback:    commandBuy,
+		},
+		"sell": {
+			name:        "sell",
+			description: "Sell an item to the Poke Mart",
+			callback:    commandSell,
+		},
+	}
+
+	config := cliConfig{ShinyOdds: *shinyOdds, AutoCorrect: *autoCorrect}
+	cache := pokecache.NewCache(cacheReapInterval,
+		pokecache.WithTTL(cacheTTL),
+		pokecache.WithStaleWhileRevalidate(cacheStaleWhileRevalidate),
+		pokecache.WithMaxStale(cacheMaxStale),
+		pokecache.WithMaxEntries(cacheMaxEntries),
+		pokecache.WithMaxBytes(cacheMaxBytes),
+	)
+	client := pokeapi.NewClient(cache, pokeapi.DefaultBaseURL)
+	client.OnStale = func(url string, staleFor time.Duration, err error) {
+		fmt.Printf("warning: showing stale data for %s, %s out of date: %v\n", url, staleFor.Round(time.Second), err)
+	}
+	rng := newRand(*seed)
+
+	player, err := trainer.Load(*savePath)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	index, err := search.Load(*indexPath)
+	if err != nil {
+		fmt.Println("warning:", err)
+		index = search.New()
 	}
+	config.Index = index
+	config.Interactive = isTerminal(os.Stdin)
 
-	var config cliConfig
-	cache := pokecache.NewCache(5 * time.Second)
+	fmt.Printf("Random seed: %d\n", *seed)
 
 	reader := bufio.NewScanner(os.Stdin)
 	for {
@@ -243,27 +835,33 @@ func main() {
 			continue
 		}
 
-		commandName := words[0]
+		commandName := resourceName(words[0])
 
 		var locationName string
 		var pokemonName string
 
 		command, exists := commands[commandName]
 		if exists {
-			if len(words) == 2 {
+			if len(words) >= 2 {
 				switch command.name {
 				case "explore":
-					locationName = words[1]
+					locationName = resourceName(words[1])
 				case "catch":
 					fallthrough
 				case "inspect":
-					pokemonName = words[1]
+					pokemonName = resourceName(words[1])
 				}
 			}
 
 			ctx := &commandContext{
-				Cache:        cache,
+				Input:        reader,
+				Client:       client,
+				Rand:         rng,
 				Config:       &config,
+				Trainer:      player,
+				SavePath:     *savePath,
+				IndexPath:    *indexPath,
+				Args:         words[1:],
 				LocationName: locationName,
 				PokemonName:  pokemonName,
 			}
@@ -274,7 +872,7 @@ func main() {
 			}
 			continue
 		} else {
-			fmt.Println("Unknown command")
+			unknownCommand(commandName)
 			continue
 		}
 	}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ArturM94/pokedexcli/internal/fuzzy"
+	"github.com/ArturM94/pokedexcli/internal/pokeapi"
+)
+
+// indexedResources are the kinds of resources kept in the search index.
+var indexedResources = []string{"pokemon", "move", "item", "location-area"}
+
+const maxSuggestions = 3
+
+// indexedNames returns every name of a resource, fetching them from the list
+// endpoint the first time and saving them to the search index so they are
+// available offline from then on.
+func indexedNames(ctx *commandContext, resource string) ([]string, error) {
+	idx := ctx.Config.Index
+	if !idx.Has(resource) {
+		var names []string
+		for r, err := range ctx.Client.List(context.Background(), resource) {
+			if err != nil {
+				return nil, err
+			}
+
+			names = append(names, r.Name)
+		}
+
+		idx.Set(resource, names)
+		saveIndex(ctx)
+	}
+
+	return idx.Names(resource), nil
+}
+
+// indexLocalized adds the localized names of a resource that was just
+// fetched to the search index.
+func indexLocalized(ctx *commandContext, resource, name string, names []pokeapi.Name) {
+	changed := false
+	for _, n := range names {
+		if ctx.Config.Index.AddLocalized(resource, name, n.Language.Name, n.Name) {
+			changed = true
+		}
+	}
+
+	if changed {
+		saveIndex(ctx)
+	}
+}
+
+// saveIndex writes the search index. It is only a cache, so failing to save
+// it is reported without failing the command.
+func saveIndex(ctx *commandContext) {
+	if err := ctx.Config.Index.Save(ctx.IndexPath); err != nil {
+		fmt.Println("warning:", err)
+	}
+}
+
+// correctName checks name against the index of resource names. It returns
+// the name to look up, which is the closest match when auto-correct is on
+// and there is a clear winner. When the name is unknown it prints the
+// closest matches and returns false. Empty names, IDs and names that can't be
+// checked because the index is unavailable are passed through unchanged.
+func correctName(ctx *commandContext, resource, name string) (string, bool) {
+	if _, err := strconv.Atoi(name); err == nil || name == "" {
+		return name, true
+	}
+
+	names, err := indexedNames(ctx, resource)
+	if err != nil {
+		return name, true
+	}
+
+	if ctx.Config.Index.Contains(resource, name) {
+		return name, true
+	}
+
+	matches := fuzzy.Closest(name, names, maxSuggestions)
+	if ctx.Config.AutoCorrect && fuzzy.Clear(matches) {
+		fmt.Printf("There is no %s called %s, assuming %s.\n", resource, name, matches[0].Name)
+
+		return matches[0].Name, true
+	}
+
+	fmt.Printf("There is no %s called %s.", resource, name)
+	if len(matches) > 0 {
+		fmt.Print(" Did you mean " + suggestions(matches) + "?")
+	}
+	fmt.Println()
+
+	return "", false
+}
+
+// unknownCommand tells the user the command doesn't exist, suggesting the
+// closest ones.
+func unknownCommand(name string) {
+	var names []string
+	for commandName := range commands {
+		names = append(names, commandName)
+	}
+
+	fmt.Print("Unknown command")
+	if matches := fuzzy.Closest(name, names, maxSuggestions); len(matches) > 0 {
+		fmt.Print(". Did you mean " + suggestions(matches) + "?")
+	}
+	fmt.Println()
+}
+
+func suggestions(matches []fuzzy.Match) string {
+	names := make([]string, len(matches))
+	for i, m := range matches {
+		names[i] = m.Name
+	}
+
+	if len(names) == 1 {
+		return names[0]
+	}
+
+	return strings.Join(names[:len(names)-1], ", ") + " or " + names[len(names)-1]
+}
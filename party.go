@@ -0,0 +1,352 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ArturM94/pokedexcli/internal/trainer"
+)
+
+func commandParty(ctx *commandContext) error {
+	if len(ctx.Trainer.Party) == 0 {
+		fmt.Println("Your party is empty.")
+
+		return nil
+	}
+
+	fmt.Println("Your party:")
+	for i, p := range ctx.Trainer.Party {
+		fmt.Printf("  %d. %s\n", i+1, describePokemon(p))
+	}
+
+	return nil
+}
+
+func commandBox(ctx *commandContext) error {
+	boxIndex := 0
+	if len(ctx.Args) > 0 {
+		var err error
+		boxIndex, err = parseSlot(ctx.Args[0])
+		if err != nil {
+			return err
+		}
+	}
+
+	box, err := ctx.Trainer.Box(boxIndex)
+	if err != nil {
+		return fmt.Errorf("error opening box: %w", err)
+	}
+
+	fmt.Printf("%s (%d/%d):\n", box.Name, len(box.Pokemon), trainer.BoxSize)
+	for i, p := range box.Pokemon {
+		fmt.Printf("  %d. %s\n", i+1, describePokemon(p))
+	}
+
+	return nil
+}
+
+func commandDeposit(ctx *commandContext) error {
+	if len(ctx.Args) == 0 {
+		fmt.Println("usage: deposit <party slot> [box]")
+
+		return nil
+	}
+
+	if inBattle(ctx) {
+		return nil
+	}
+
+	slot, err := parseSlot(ctx.Args[0])
+	if err != nil {
+		return err
+	}
+
+	boxIndex := firstBoxWithRoom(ctx.Trainer)
+	if len(ctx.Args) > 1 {
+		boxIndex, err = parseSlot(ctx.Args[1])
+		if err != nil {
+			return err
+		}
+	}
+
+	var p *trainer.Pokemon
+	err = ctx.Trainer.Transact(ctx.SavePath, func(t *trainer.Trainer) error {
+		if slot < len(t.Party) {
+			p = t.Party[slot]
+		}
+
+		return t.Deposit(slot, boxIndex)
+	})
+	if err != nil {
+		return fmt.Errorf("error depositing: %w", err)
+	}
+
+	fmt.Printf("%s was deposited in %s.\n", p.Name(), ctx.Trainer.Boxes[boxIndex].Name)
+
+	return nil
+}
+
+func commandWithdraw(ctx *commandContext) error {
+	if len(ctx.Args) < 2 {
+		fmt.Println("usage: withdraw <box> <slot>")
+
+		return nil
+	}
+
+	if inBattle(ctx) {
+		return nil
+	}
+
+	boxIndex, err := parseSlot(ctx.Args[0])
+	if err != nil {
+		return err
+	}
+
+	slot, err := parseSlot(ctx.Args[1])
+	if err != nil {
+		return err
+	}
+
+	err = ctx.Trainer.Transact(ctx.SavePath, func(t *trainer.Trainer) error {
+		return t.Withdraw(boxIndex, slot)
+	})
+	if err != nil {
+		return fmt.Errorf("error withdrawing: %w", err)
+	}
+
+	p := ctx.Trainer.Party[len(ctx.Trainer.Party)-1]
+	fmt.Printf("%s joined your party.\n", p.Name())
+
+	return nil
+}
+
+func commandSwap(ctx *commandContext) error {
+	if len(ctx.Args) < 2 {
+		fmt.Println("usage: swap <party slot> <party slot>")
+
+		return nil
+	}
+
+	if inBattle(ctx) {
+		return nil
+	}
+
+	a, err := parseSlot(ctx.Args[0])
+	if err != nil {
+		return err
+	}
+
+	b, err := parseSlot(ctx.Args[1])
+	if err != nil {
+		return err
+	}
+
+	err = ctx.Trainer.Transact(ctx.SavePath, func(t *trainer.Trainer) error {
+		return t.Swap(a, b)
+	})
+	if err != nil {
+		return fmt.Errorf("error swapping: %w", err)
+	}
+
+	return commandParty(ctx)
+}
+
+func commandRelease(ctx *commandContext) error {
+	if len(ctx.Args) == 0 {
+		fmt.Println("usage: release <party slot> | release <box> <slot>")
+
+		return nil
+	}
+
+	if inBattle(ctx) {
+		return nil
+	}
+
+	var slots []int
+	for _, arg := range ctx.Args[:min(len(ctx.Args), 2)] {
+		slot, err := parseSlot(arg)
+		if err != nil {
+			return err
+		}
+
+		slots = append(slots, slot)
+	}
+
+	var released *trainer.Pokemon
+	err := ctx.Trainer.Transact(ctx.SavePath, func(t *trainer.Trainer) error {
+		var err error
+		if len(slots) == 1 {
+			released, err = t.ReleaseFromParty(slots[0])
+		} else {
+			released, err = t.ReleaseFromBox(slots[0], slots[1])
+		}
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("error releasing: %w", err)
+	}
+
+	fmt.Println(released.Name() + " was released. Bye-bye, " + released.Name() + "!")
+
+	return nil
+}
+
+// resolvePokemon finds an owned Pokemon by instance ID (e.g. 12 or #12), by
+// nickname or by species, asking which one is meant when several match. It
+// returns nil after telling the user when there is no match.
+func resolvePokemon(ctx *commandContext, ref string) (*trainer.Pokemon, error) {
+	if ref == "" {
+		fmt.Println("Which Pokemon? Give a name or an ID.")
+
+		return nil, nil
+	}
+
+	if id, err := strconv.Atoi(strings.TrimPrefix(ref, "#")); err == nil {
+		p := ctx.Trainer.PokemonByID(id)
+		if p == nil {
+			fmt.Printf("You don't have a Pokemon with ID %d!\n", id)
+		}
+
+		return p, nil
+	}
+
+	matches := ctx.Trainer.PokemonByName(ref)
+	switch len(matches) {
+	case 0:
+		fmt.Println("You has not caught " + ref + "!")
+
+		return nil, nil
+	case 1:
+		return matches[0], nil
+	}
+
+	fmt.Printf("You have %d Pokemon called %s:\n", len(matches), ref)
+	for _, p := range matches {
+		fmt.Println("  " + describePokemon(p))
+	}
+
+	answer, ok := prompt(ctx, "Which one? Enter its ID: ")
+	if !ok {
+		return nil, nil
+	}
+
+	id, err := strconv.Atoi(strings.TrimPrefix(answer, "#"))
+	if err == nil {
+		for _, p := range matches {
+			if p.ID == id {
+				return p, nil
+			}
+		}
+	}
+
+	fmt.Println("That's not one of them.")
+
+	return nil, nil
+}
+
+// prompt asks the user a question and reads one line of answer. It reports
+// false when the input has ended, and without asking when the input isn't a
+// terminal, so piped commands are never taken for answers.
+func prompt(ctx *commandContext, question string) (string, bool) {
+	if !ctx.Config.Interactive {
+		return "", false
+	}
+
+	fmt.Print(question)
+
+	if !ctx.Input.Scan() {
+		return "", false
+	}
+
+	return strings.TrimSpace(ctx.Input.Text()), true
+}
+
+func commandNickname(ctx *commandContext) error {
+	if len(ctx.Args) < 2 {
+		fmt.Println(`usage: nickname <pokemon> "<nickname>"`)
+
+		return nil
+	}
+
+	p, err := resolvePokemon(ctx, ctx.Args[0])
+	if err != nil || p == nil {
+		return err
+	}
+
+	return setNickname(ctx, p, strings.Join(ctx.Args[1:], " "))
+}
+
+// setNickname names p, or clears its nickname when nickname is empty.
+func setNickname(ctx *commandContext, p *trainer.Pokemon, nickname string) error {
+	nickname, err := trainer.ValidateNickname(nickname)
+	if err != nil {
+		return err
+	}
+
+	err = ctx.Trainer.Transact(ctx.SavePath, func(t *trainer.Trainer) error {
+		t.PokemonByID(p.ID).Nickname = nickname
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error saving trainer: %w", err)
+	}
+
+	if nickname == "" {
+		fmt.Println(p.Species + " no longer has a nickname.")
+	} else {
+		fmt.Println(p.Species + " is now called " + nickname + ".")
+	}
+
+	return nil
+}
+
+func describePokemon(p *trainer.Pokemon) string {
+	name := p.Species
+	if p.Nickname != "" {
+		name = fmt.Sprintf("%s (%s)", p.Nickname, p.Species)
+	}
+
+	description := fmt.Sprintf("#%d %s Lv. %d HP %d", p.ID, name, p.Level, p.HP)
+	if p.Shiny {
+		description += " (shiny)"
+	}
+	if p.HP == 0 {
+		description += " (fainted)"
+	}
+
+	return description
+}
+
+// parseSlot turns a 1-based slot or box number into an index.
+func parseSlot(arg string) (int, error) {
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid number: %s", arg)
+	}
+
+	return n - 1, nil
+}
+
+func firstBoxWithRoom(t *trainer.Trainer) int {
+	for i, box := range t.Boxes {
+		if len(box.Pokemon) < trainer.BoxSize {
+			return i
+		}
+	}
+
+	return 0
+}
+
+// inBattle stops commands that rearrange the party while a battle is on.
+func inBattle(ctx *commandContext) bool {
+	if ctx.Config.Battle == nil {
+		return false
+	}
+
+	fmt.Println("You can't do that during a battle.")
+
+	return true
+}
@@ -0,0 +1,369 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ArturM94/pokedexcli/internal/battle"
+	"github.com/ArturM94/pokedexcli/internal/pokeapi"
+	"github.com/ArturM94/pokedexcli/internal/trainer"
+)
+
+// dexRow is one line of the pokedex table.
+type dexRow struct {
+	ID      int
+	Name    string
+	Caught  bool
+	Missing bool
+	Types   []string
+	Stats   battle.Stats
+	BST     int
+	// Level is the highest level of an owned Pokemon of the species.
+	Level int
+	// Shiny is set when a shiny Pokemon of the species is owned.
+	Shiny bool
+	// Nicknames of the owned Pokemon of the species.
+	Nicknames []string
+	loaded    bool
+}
+
+// dexFilter is a condition such as speed>90 on a dexRow.
+type dexFilter struct {
+	field string
+	op    string
+	value int
+}
+
+var dexFilterPattern = regexp.MustCompile(`^([a-z-]+)\s*(>=|<=|!=|==|=|>|<)\s*(\d+)$`)
+
+var dexSorts = map[string]func(a, b dexRow) int{
+	"id": func(a, b dexRow) int {
+		return cmp.Compare(a.ID, b.ID)
+	},
+	"name": func(a, b dexRow) int {
+		return cmp.Compare(a.Name, b.Name)
+	},
+	"caught": func(a, b dexRow) int {
+		if a.Caught != b.Caught {
+			if a.Caught {
+				return -1
+			}
+			return 1
+		}
+		return 0
+	},
+	"level": func(a, b dexRow) int {
+		return cmp.Compare(b.Level, a.Level)
+	},
+	"bst": func(a, b dexRow) int {
+		return cmp.Compare(b.BST, a.BST)
+	},
+}
+
+// stringList collects a flag that may be given several times.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func commandPokedex(ctx *commandContext) error {
+	flags := flag.NewFlagSet("pokedex", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	sortBy := flags.String("sort", "id", "")
+	typeName := flags.String("type", "", "")
+	page := flags.Int("page", 1, "")
+	limit := flags.Int("limit", defaultPageSize, "")
+	var rawFilters stringList
+	flags.Var(&rawFilters, "filter", "")
+
+	const usage = "usage: pokedex [first|last] [--sort id|name|caught|level|bst] [--type <type>] [--filter <stat><op><value>] [--page <n>] [--limit <n>]"
+
+	args, err := parseFlags(flags, ctx.Args)
+	if err != nil {
+		return fmt.Errorf("%s: %w", usage, err)
+	}
+	*sortBy, *typeName = resourceName(*sortBy), resourceName(*typeName)
+	for i := range args {
+		args[i] = resourceName(args[i])
+	}
+
+	if *limit < 1 || len(args) > 1 || (len(args) == 1 && args[0] != "first" && args[0] != "last") {
+		return errors.New(usage)
+	}
+
+	compare, ok := dexSorts[*sortBy]
+	if !ok {
+		return fmt.Errorf("unknown sort: %s", *sortBy)
+	}
+
+	var filters []dexFilter
+	for _, raw := range rawFilters {
+		f, err := parseDexFilter(raw)
+		if err != nil {
+			return err
+		}
+
+		filters = append(filters, f)
+	}
+
+	printCompletion(ctx.Trainer)
+
+	rows := dexRows(ctx.Trainer)
+	if len(rows) == 0 {
+		return nil
+	}
+
+	// Species data is only fetched for every row when the whole list
+	// depends on it; otherwise just the shown page is loaded below.
+	if *typeName != "" || len(filters) > 0 || *sortBy == "bst" {
+		for i := range rows {
+			if err := loadDexRow(ctx, &rows[i]); err != nil {
+				return err
+			}
+		}
+
+		rows = slices.DeleteFunc(rows, func(row dexRow) bool {
+			return !row.matches(*typeName, filters)
+		})
+	} else if *sortBy == "id" {
+		rows = withMissing(rows)
+	}
+
+	slices.SortStableFunc(rows, func(a, b dexRow) int {
+		if c := compare(a, b); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	pages := pageCount(len(rows), *limit)
+	if len(args) == 1 && args[0] == "first" {
+		*page = 1
+	} else if len(args) == 1 && args[0] == "last" {
+		*page = pages
+	}
+
+	if *page < 1 || *page > pages {
+		return fmt.Errorf("page %d is out of range 1-%d", *page, pages)
+	}
+
+	start := (*page - 1) * *limit
+	shown := rows[start:min(start+*limit, len(rows))]
+
+	fmt.Println()
+	fmt.Printf("%-6s %-16s %-18s %4s  %s\n", "No.", "Name", "Types", "BST", "Status")
+	for i := range shown {
+		row := &shown[i]
+		if row.Missing {
+			fmt.Printf("#%04d %s\n", row.ID, "???")
+			continue
+		}
+
+		if err := loadDexRow(ctx, row); err != nil {
+			return err
+		}
+
+		status := "seen"
+		if row.Caught {
+			status = "caught"
+		}
+		if row.Level > 0 {
+			status += fmt.Sprintf(" Lv. %d", row.Level)
+		}
+		if row.Shiny {
+			status += " shiny"
+		}
+		if len(row.Nicknames) > 0 {
+			status += " \"" + strings.Join(row.Nicknames, "\", \"") + "\""
+		}
+
+		fmt.Printf("#%04d %-16s %-18s %4d  %s\n", row.ID, row.Name, strings.Join(row.Types, "/"), row.BST, status)
+	}
+	fmt.Printf("Page %d of %d (%d entries)\n", *page, pages, len(rows))
+
+	return nil
+}
+
+func printCompletion(t *trainer.Trainer) {
+	seen, caught := t.Completion(1, trainer.NationalDexSize)
+	fmt.Printf("National Pokedex: seen %s, caught %s\n",
+		completion(seen, trainer.NationalDexSize), completion(caught, trainer.NationalDexSize))
+
+	for _, gen := range trainer.Generations {
+		size := gen.Last - gen.First + 1
+		seen, caught := t.Completion(gen.First, gen.Last)
+		fmt.Printf("  %s (%s): seen %s, caught %s\n",
+			gen.Name, gen.Region, completion(seen, size), completion(caught, size))
+	}
+}
+
+func completion(count, total int) string {
+	return fmt.Sprintf("%d/%d (%.1f%%)", count, total, float64(count)*100/float64(total))
+}
+
+// dexRows lists every species in the trainer's Pokedex, in dex order.
+func dexRows(t *trainer.Trainer) []dexRow {
+	levels := map[string]int{}
+	shiny := map[string]bool{}
+	nicknames := map[string][]string{}
+	for _, p := range t.AllPokemon() {
+		levels[p.Species] = max(levels[p.Species], p.Level)
+		shiny[p.Species] = shiny[p.Species] || p.Shiny
+		if p.Nickname != "" {
+			nicknames[p.Species] = append(nicknames[p.Species], p.Nickname)
+		}
+	}
+
+	var rows []dexRow
+	for id, entry := range t.Pokedex {
+		rows = append(rows, dexRow{
+			ID:        id,
+			Name:      entry.Name,
+			Caught:    entry.Caught,
+			Level:     levels[entry.Name],
+			Shiny:     shiny[entry.Name],
+			Nicknames: nicknames[entry.Name],
+		})
+	}
+
+	slices.SortFunc(rows, func(a, b dexRow) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return rows
+}
+
+// withMissing fills the gaps between dex numbers with placeholder rows. Only
+// numbers in the National Pokedex are filled in.
+func withMissing(rows []dexRow) []dexRow {
+	var filled []dexRow
+	for _, row := range rows {
+		next := 1
+		if len(filled) > 0 {
+			next = filled[len(filled)-1].ID + 1
+		}
+
+		for id := next; id < min(row.ID, trainer.NationalDexSize+1); id++ {
+			filled = append(filled, dexRow{ID: id, Missing: true})
+		}
+
+		filled = append(filled, row)
+	}
+
+	return filled
+}
+
+// dexNumber is the national dex number of a Pokemon: the ID of its species,
+// which alternate forms such as deoxys-attack share with the default form.
+func dexNumber(pokemon *pokeapi.GetPokemonResponse) (int, error) {
+	return pokeapi.IDFromURL(pokemon.Species.URL)
+}
+
+func loadDexRow(ctx *commandContext, row *dexRow) error {
+	if row.loaded || row.Missing {
+		return nil
+	}
+
+	species, err := ctx.Client.GetPokemon(strconv.Itoa(row.ID))
+	if err != nil {
+		return fmt.Errorf("error getting pokemon: %w", err)
+	}
+
+	row.Types = typeNames(species)
+	row.Stats = baseStats(species)
+	row.BST = row.Stats.HP + row.Stats.Attack + row.Stats.Defense +
+		row.Stats.SpecialAttack + row.Stats.SpecialDefense + row.Stats.Speed
+	row.loaded = true
+
+	return nil
+}
+
+func parseDexFilter(raw string) (dexFilter, error) {
+	match := dexFilterPattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(raw)))
+	if match == nil {
+		return dexFilter{}, fmt.Errorf("invalid filter %q, expected e.g. speed>90", raw)
+	}
+
+	f := dexFilter{field: match[1], op: match[2]}
+	if f.op == "=" {
+		f.op = "=="
+	}
+
+	if _, ok := (dexRow{}).field(f.field); !ok {
+		return dexFilter{}, fmt.Errorf("unknown filter field: %s", f.field)
+	}
+
+	f.value, _ = strconv.Atoi(match[3])
+
+	return f, nil
+}
+
+func (row dexRow) field(name string) (int, bool) {
+	switch name {
+	case "id":
+		return row.ID, true
+	case "level":
+		return row.Level, true
+	case "bst":
+		return row.BST, true
+	case "hp":
+		return row.Stats.HP, true
+	case "attack":
+		return row.Stats.Attack, true
+	case "defense":
+		return row.Stats.Defense, true
+	case "special-attack":
+		return row.Stats.SpecialAttack, true
+	case "special-defense":
+		return row.Stats.SpecialDefense, true
+	case "speed":
+		return row.Stats.Speed, true
+	default:
+		return 0, false
+	}
+}
+
+func (row dexRow) matches(typeName string, filters []dexFilter) bool {
+	if typeName != "" && !slices.Contains(row.Types, typeName) {
+		return false
+	}
+
+	for _, f := range filters {
+		v, _ := row.field(f.field)
+
+		var ok bool
+		switch f.op {
+		case ">":
+			ok = v > f.value
+		case ">=":
+			ok = v >= f.value
+		case "<":
+			ok = v < f.value
+		case "<=":
+			ok = v <= f.value
+		case "==":
+			ok = v == f.value
+		case "!=":
+			ok = v != f.value
+		}
+
+		if !ok {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/ArturM94/pokedexcli/internal/battle"
+	"github.com/ArturM94/pokedexcli/internal/trainer"
+)
+
+func TestParseDexFilter(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected dexFilter
+		err      bool
+	}{
+		{input: "speed>90", expected: dexFilter{field: "speed", op: ">", value: 90}},
+		{input: "BST >= 500", expected: dexFilter{field: "bst", op: ">=", value: 500}},
+		{input: "level=5", expected: dexFilter{field: "level", op: "==", value: 5}},
+		{input: "special-attack!=10", expected: dexFilter{field: "special-attack", op: "!=", value: 10}},
+		{input: "speed", err: true},
+		{input: "shininess>1", err: true},
+	}
+
+	for _, c := range cases {
+		actual, err := parseDexFilter(c.input)
+		if c.err {
+			if err == nil {
+				t.Errorf("parseDexFilter(%q) expected an error", c.input)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("parseDexFilter(%q) unexpected error: %v", c.input, err)
+			continue
+		}
+
+		if actual != c.expected {
+			t.Errorf("parseDexFilter(%q) == %+v, want %+v", c.input, actual, c.expected)
+		}
+	}
+}
+
+func TestDexRowMatches(t *testing.T) {
+	row := dexRow{
+		ID:    6,
+		Name:  "charizard",
+		Types: []string{"fire", "flying"},
+		Stats: battle.Stats{Speed: 100},
+		BST:   534,
+	}
+
+	cases := []struct {
+		typeName string
+		filters  []string
+		expected bool
+	}{
+		{typeName: "fire", expected: true},
+		{typeName: "water", expected: false},
+		{filters: []string{"speed>90"}, expected: true},
+		{filters: []string{"speed>90", "bst<500"}, expected: false},
+		{typeName: "flying", filters: []string{"bst>=534"}, expected: true},
+	}
+
+	for _, c := range cases {
+		var filters []dexFilter
+		for _, raw := range c.filters {
+			f, err := parseDexFilter(raw)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			filters = append(filters, f)
+		}
+
+		if actual := row.matches(c.typeName, filters); actual != c.expected {
+			t.Errorf("matches(%q, %v) == %v, want %v", c.typeName, c.filters, actual, c.expected)
+		}
+	}
+}
+
+func TestWithMissing(t *testing.T) {
+	rows := withMissing([]dexRow{{ID: 2}, {ID: 5}})
+
+	expected := []struct {
+		id      int
+		missing bool
+	}{
+		{id: 1, missing: true},
+		{id: 2},
+		{id: 3, missing: true},
+		{id: 4, missing: true},
+		{id: 5},
+	}
+
+	if len(rows) != len(expected) {
+		t.Fatalf("expected %d rows, got %d", len(expected), len(rows))
+	}
+
+	for i, e := range expected {
+		if rows[i].ID != e.id || rows[i].Missing != e.missing {
+			t.Errorf("row %d == #%d missing %v, want #%d missing %v", i, rows[i].ID, rows[i].Missing, e.id, e.missing)
+		}
+	}
+}
+
+func TestWithMissingStopsAtNationalDex(t *testing.T) {
+	rows := withMissing([]dexRow{{ID: trainer.NationalDexSize}, {ID: 10001}})
+
+	if len(rows) != trainer.NationalDexSize+1 {
+		t.Fatalf("expected %d rows, got %d", trainer.NationalDexSize+1, len(rows))
+	}
+
+	if last := rows[len(rows)-1]; last.ID != 10001 || last.Missing {
+		t.Errorf("expected the last row to be #10001, got #%d missing %v", last.ID, last.Missing)
+	}
+}
@@ -1,6 +1,10 @@
 package main
 
-import "testing"
+import (
+	"flag"
+	"slices"
+	"testing"
+)
 
 func TestCleanInput(t *testing.T) {
 	cases := []struct {
@@ -19,6 +23,38 @@ func TestCleanInput(t *testing.T) {
 			input:    "  hello  world  ",
 			expected: []string{"hello", "world"},
 		},
+		{
+			input:    "Catch\tPIKACHU\n",
+			expected: []string{"Catch", "PIKACHU"},
+		},
+		{
+			input:    `nickname pikachu "Sparky the Great"`,
+			expected: []string{"nickname", "pikachu", "Sparky the Great"},
+		},
+		{
+			input:    `nickname pikachu 'It\'s'`,
+			expected: []string{"nickname", "pikachu", `It\s`},
+		},
+		{
+			input:    `nickname pikachu It\'s\ Me`,
+			expected: []string{"nickname", "pikachu", "It's Me"},
+		},
+		{
+			input:    `say "a \"quoted\" word"`,
+			expected: []string{"say", `a "quoted" word`},
+		},
+		{
+			input:    `empty "" ''`,
+			expected: []string{"empty", "", ""},
+		},
+		{
+			input:    `open "unterminated Quote`,
+			expected: []string{"open", "unterminated Quote"},
+		},
+		{
+			input:    "   ",
+			expected: nil,
+		},
 	}
 
 	for _, c := range cases {
@@ -39,3 +75,130 @@ func TestCleanInput(t *testing.T) {
 		}
 	}
 }
+
+func TestIsCaughtSameSeed(t *testing.T) {
+	const seed = 42
+	first := newRand(seed)
+	second := newRand(seed)
+
+	for i := 0; i < 100; i++ {
+		baseExperience := i * 3
+
+		if isCaught(first, baseExperience, 1) != isCaught(second, baseExperience, 1) {
+			t.Errorf("isCaught differs for the same seed at attempt %d", i)
+		}
+	}
+}
+
+func TestRollShiny(t *testing.T) {
+	rng := newRand(42)
+
+	for i := 0; i < 100; i++ {
+		if !rollShiny(rng, 1) {
+			t.Fatalf("expected odds of 1 to always be shiny")
+		}
+
+		if rollShiny(rng, 0) {
+			t.Fatalf("expected odds of 0 to never be shiny")
+		}
+	}
+
+	shiny := 0
+	for i := 0; i < 4096*10; i++ {
+		if rollShiny(rng, 4096) {
+			shiny++
+		}
+	}
+
+	if shiny == 0 || shiny > 30 {
+		t.Errorf("expected about 10 shiny Pokemon in 40960 rolls, got %d", shiny)
+	}
+}
+
+func TestParseFlags(t *testing.T) {
+	cases := []struct {
+		args     []string
+		expected []string
+		shiny    bool
+	}{
+		{args: []string{"pikachu"}, expected: []string{"pikachu"}},
+		{args: []string{"pikachu", "--shiny"}, expected: []string{"pikachu"}, shiny: true},
+		{args: []string{"--shiny", "pikachu", "extra"}, expected: []string{"pikachu", "extra"}, shiny: true},
+		{args: nil, expected: nil},
+	}
+
+	for _, c := range cases {
+		flags := flag.NewFlagSet("test", flag.ContinueOnError)
+		shiny := flags.Bool("shiny", false, "")
+
+		actual, err := parseFlags(flags, c.args)
+		if err != nil {
+			t.Errorf("parseFlags(%q) unexpected error: %v", c.args, err)
+			continue
+		}
+
+		if !slices.Equal(actual, c.expected) || *shiny != c.shiny {
+			t.Errorf("parseFlags(%q) == %q shiny %v, want %q shiny %v", c.args, actual, *shiny, c.expected, c.shiny)
+		}
+	}
+}
+
+func TestListPageResize(t *testing.T) {
+	cases := []struct {
+		page     int
+		limit    int
+		newLimit int
+		expected int
+	}{
+		{page: 0, limit: 0, newLimit: 50, expected: 0},
+		{page: 2, limit: 20, newLimit: 50, expected: 0},
+		{page: 3, limit: 20, newLimit: 50, expected: 1},
+		{page: 1, limit: 50, newLimit: 10, expected: 5},
+	}
+
+	for _, c := range cases {
+		p := listPage{Page: c.page, Limit: c.limit}
+		p.resize(c.newLimit)
+
+		if p.Page != c.expected || p.Limit != c.newLimit {
+			t.Errorf("page %d of %d resized to %d == page %d of %d, want page %d", c.page, c.limit, c.newLimit, p.Page, p.Limit, c.expected)
+		}
+	}
+}
+
+func TestPageCount(t *testing.T) {
+	cases := []struct {
+		count    int
+		limit    int
+		expected int
+	}{
+		{count: 0, limit: 20, expected: 1},
+		{count: 20, limit: 20, expected: 1},
+		{count: 21, limit: 20, expected: 2},
+		{count: 1089, limit: 20, expected: 55},
+	}
+
+	for _, c := range cases {
+		if actual := pageCount(c.count, c.limit); actual != c.expected {
+			t.Errorf("pageCount(%d, %d) == %d, want %d", c.count, c.limit, actual, c.expected)
+		}
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	cases := []struct {
+		n        int
+		expected string
+	}{
+		{n: 0, expected: "0 B"},
+		{n: 1023, expected: "1023 B"},
+		{n: 1536, expected: "1.5 KiB"},
+		{n: 64 << 20, expected: "64.0 MiB"},
+	}
+
+	for _, c := range cases {
+		if actual := formatBytes(c.n); actual != c.expected {
+			t.Errorf("formatBytes(%d) == %q, want %q", c.n, actual, c.expected)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/ArturM94/pokedexcli/internal/search"
+)
+
+const defaultSearchLimit = 10
+
+func commandSearch(ctx *commandContext) error {
+	flags := flag.NewFlagSet("search", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	kind := flags.String("kind", "", "")
+	limit := flags.Int("limit", defaultSearchLimit, "")
+
+	args, err := parseFlags(flags, ctx.Args)
+	if err != nil || len(args) == 0 || *limit < 1 {
+		fmt.Println("usage: search [--kind pokemon|move|item|location-area] [--limit <n>] <query>")
+
+		return nil
+	}
+
+	*kind = resourceName(*kind)
+
+	kinds := indexedResources
+	if *kind != "" {
+		if !slices.Contains(indexedResources, *kind) {
+			return fmt.Errorf("unknown kind: %s", *kind)
+		}
+
+		kinds = []string{*kind}
+	}
+
+	// Kinds that can't be listed right now are left out rather than
+	// failing the whole search.
+	var available []string
+	for _, k := range kinds {
+		if _, err := indexedNames(ctx, k); err != nil {
+			fmt.Println("warning:", err)
+			continue
+		}
+
+		available = append(available, k)
+	}
+
+	query := strings.Join(args, " ")
+
+	results := ctx.Config.Index.Search(query, available, *limit)
+	if len(results) == 0 {
+		fmt.Println("Nothing found for " + query + ".")
+
+		return nil
+	}
+
+	for _, r := range results {
+		fmt.Printf("%-14s %s\n", r.Kind, describeResult(r))
+	}
+
+	return nil
+}
+
+func describeResult(r search.Result) string {
+	if r.Language == "" || strings.EqualFold(r.Matched, r.Name) {
+		return r.Name
+	}
+
+	return fmt.Sprintf("%s (%s: %s)", r.Name, r.Language, r.Matched)
+}
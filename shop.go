@@ -0,0 +1,170 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/ArturM94/pokedexcli/internal/trainer"
+)
+
+var shopStock = []string{
+	"poke-ball",
+	"great-ball",
+	"ultra-ball",
+	"potion",
+	"super-potion",
+	"hyper-potion",
+}
+
+var errNotEnoughMoney = errors.New("you don't have enough money")
+
+func commandShop(ctx *commandContext) error {
+	fmt.Println("Welcome to the Poke Mart!")
+	fmt.Printf("You have $%d\n", ctx.Trainer.Money)
+	fmt.Println()
+
+	for _, name := range shopStock {
+		item, err := ctx.Client.GetItem(name)
+		if err != nil {
+			return fmt.Errorf("error getting item: %w", err)
+		}
+
+		fmt.Printf(" - %-14s $%d\n", item.Name, item.Cost)
+	}
+
+	return nil
+}
+
+func commandBuy(ctx *commandContext) error {
+	if len(ctx.Args) == 0 {
+		fmt.Println("usage: buy <item> [qty]")
+
+		return nil
+	}
+
+	qty, err := parseQuantity(ctx.Args)
+	if err != nil {
+		return err
+	}
+
+	itemName := resourceName(ctx.Args[0])
+	if !slices.Contains(shopStock, itemName) {
+		fmt.Println("The Poke Mart doesn't sell " + itemName + ".")
+
+		return nil
+	}
+
+	item, err := ctx.Client.GetItem(itemName)
+	if err != nil {
+		return fmt.Errorf("error getting item: %w", err)
+	}
+
+	var total int
+	err = ctx.Trainer.Transact(ctx.SavePath, func(t *trainer.Trainer) error {
+		var err error
+		total, err = purchaseTotal(item.Cost, qty, t.Money)
+		if err != nil {
+			return err
+		}
+
+		t.Money -= total
+		t.AddItem(item.Name, qty)
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error buying %s: %w", item.Name, err)
+	}
+
+	fmt.Printf("Bought %d %s for $%d. You have $%d left.\n", qty, item.Name, total, ctx.Trainer.Money)
+
+	return nil
+}
+
+func commandSell(ctx *commandContext) error {
+	if len(ctx.Args) == 0 {
+		fmt.Println("usage: sell <item> [qty]")
+
+		return nil
+	}
+
+	qty, err := parseQuantity(ctx.Args)
+	if err != nil {
+		return err
+	}
+
+	itemName := resourceName(ctx.Args[0])
+	if ctx.Trainer.ItemCount(itemName) < qty {
+		fmt.Printf("You don't have %d %s!\n", qty, itemName)
+
+		return nil
+	}
+
+	item, err := ctx.Client.GetItem(itemName)
+	if err != nil {
+		return fmt.Errorf("error getting item: %w", err)
+	}
+
+	if item.Cost == 0 {
+		fmt.Println(item.Name + " can't be sold.")
+
+		return nil
+	}
+
+	total := item.Cost / 2 * qty
+
+	err = ctx.Trainer.Transact(ctx.SavePath, func(t *trainer.Trainer) error {
+		if !t.RemoveItem(item.Name, qty) {
+			return fmt.Errorf("you don't have %d %s", qty, item.Name)
+		}
+
+		t.Money += total
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error selling %s: %w", item.Name, err)
+	}
+
+	fmt.Printf("Sold %d %s for $%d. You have $%d now.\n", qty, item.Name, total, ctx.Trainer.Money)
+
+	return nil
+}
+
+func commandMoney(ctx *commandContext) error {
+	fmt.Printf("You have $%d\n", ctx.Trainer.Money)
+
+	return nil
+}
+
+// parseQuantity reads the optional quantity following the item name.
+func parseQuantity(args []string) (int, error) {
+	if len(args) < 2 {
+		return 1, nil
+	}
+
+	qty, err := strconv.Atoi(args[1])
+	if err != nil || qty < 1 {
+		return 0, fmt.Errorf("invalid quantity: %s", args[1])
+	}
+
+	return qty, nil
+}
+
+// purchaseTotal is what qty items costing cost each come to, or
+// errNotEnoughMoney when that is more than money. The check is made before
+// multiplying so a huge qty can't overflow into a negative total.
+func purchaseTotal(cost, qty, money int) (int, error) {
+	if cost > 0 && qty > money/cost {
+		return 0, errNotEnoughMoney
+	}
+
+	return cost * qty, nil
+}
+
+// catchReward is the prize money for catching a Pokemon.
+func catchReward(baseExperience int) int {
+	return baseExperience * 2
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPurchaseTotal(t *testing.T) {
+	cases := []struct {
+		cost     int
+		qty      int
+		money    int
+		expected int
+		err      error
+	}{
+		{cost: 200, qty: 3, money: 3000, expected: 600},
+		{cost: 200, qty: 15, money: 3000, expected: 3000},
+		{cost: 200, qty: 16, money: 3000, err: errNotEnoughMoney},
+		{cost: 200, qty: 46116860184273880, money: 3000, err: errNotEnoughMoney},
+		{cost: 0, qty: 5, money: 0, expected: 0},
+	}
+
+	for _, c := range cases {
+		actual, err := purchaseTotal(c.cost, c.qty, c.money)
+		if !errors.Is(err, c.err) || actual != c.expected {
+			t.Errorf("purchaseTotal(%d, %d, %d) == %d, %v, want %d, %v", c.cost, c.qty, c.money, actual, err, c.expected, c.err)
+		}
+	}
+}
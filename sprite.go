@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ArturM94/pokedexcli/internal/pokeapi"
+	"github.com/ArturM94/pokedexcli/internal/render"
+)
+
+// spriteMaxWidth keeps sprites from wrapping in a standard 80 column terminal.
+const spriteMaxWidth = 64
+
+type spriteOptions struct {
+	shiny   bool
+	back    bool
+	version string
+}
+
+func commandSprite(ctx *commandContext) error {
+	var opts spriteOptions
+
+	flags := flag.NewFlagSet("sprite", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	flags.BoolVar(&opts.shiny, "shiny", false, "")
+	flags.BoolVar(&opts.back, "back", false, "")
+	flags.StringVar(&opts.version, "version", "", "")
+	modeName := flags.String("mode", "", "")
+
+	args, err := parseFlags(flags, ctx.Args)
+	if err != nil || len(args) == 0 {
+		fmt.Println("usage: sprite <pokemon> [--shiny] [--back] [--version <version>] [--mode truecolor|256|ascii]")
+
+		return nil
+	}
+
+	mode := render.DetectMode(os.Getenv)
+	if *modeName != "" {
+		mode, err = render.ParseMode(resourceName(*modeName))
+		if err != nil {
+			return err
+		}
+	}
+
+	name, ok := correctName(ctx, "pokemon", resourceName(args[0]))
+	if !ok {
+		return nil
+	}
+
+	pokemon, err := ctx.Client.GetPokemon(name)
+	if err != nil {
+		return fmt.Errorf("error getting pokemon: %w", err)
+	}
+
+	url, err := spriteURL(pokemon, opts)
+	if err != nil {
+		return err
+	}
+
+	return printSprite(ctx, url, mode)
+}
+
+func printSprite(ctx *commandContext, url string, mode render.Mode) error {
+	data, err := ctx.Client.GetSprite(url)
+	if err != nil {
+		return fmt.Errorf("error getting sprite: %w", err)
+	}
+
+	img, err := render.Decode(data)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(render.Render(img, render.Options{Mode: mode, MaxWidth: spriteMaxWidth}))
+
+	return nil
+}
+
+// spriteURL picks a sprite, using the shiny one for shiny Pokemon. Without a
+// version the latest sprite is used.
+func spriteURL(pokemon *pokeapi.GetPokemonResponse, opts spriteOptions) (string, error) {
+	sprites := map[string]any{
+		"front_default": pokemon.Sprites.FrontDefault,
+		"front_shiny":   pokemon.Sprites.FrontShiny,
+		"back_default":  pokemon.Sprites.BackDefault,
+		"back_shiny":    pokemon.Sprites.BackShiny,
+	}
+
+	if opts.version != "" {
+		var err error
+		sprites, err = versionSprites(pokemon, resourceName(opts.version))
+		if err != nil {
+			return "", err
+		}
+	}
+
+	key := "front_"
+	if opts.back {
+		key = "back_"
+	}
+
+	if opts.shiny {
+		key += "shiny"
+	} else {
+		key += "default"
+	}
+
+	url, _ := sprites[key].(string)
+	if url == "" {
+		return "", fmt.Errorf("%s has no %s sprite", pokemon.Name, key)
+	}
+
+	return url, nil
+}
+
+// versionSprites finds the sprites of a game version such as red-blue. The
+// versions are nested by generation, so they are looked up through their
+// JSON form rather than field by field.
+func versionSprites(pokemon *pokeapi.GetPokemonResponse, version string) (map[string]any, error) {
+	data, err := json.Marshal(pokemon.Sprites.Versions)
+	if err != nil {
+		return nil, fmt.Errorf("error reading sprites: %w", err)
+	}
+
+	var generations map[string]map[string]map[string]any
+	if err := json.Unmarshal(data, &generations); err != nil {
+		return nil, fmt.Errorf("error reading sprites: %w", err)
+	}
+
+	for _, versions := range generations {
+		if sprites, ok := versions[version]; ok {
+			return sprites, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown sprite version: %s", version)
+}